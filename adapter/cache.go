@@ -0,0 +1,41 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/pinguo/pgo2"
+	"github.com/pinguo/pgo2/client/redis"
+	"github.com/pinguo/pgo2/iface"
+)
+
+func init() {
+	pgo2.RegisterCacheStore(pgo2.CacheBackendRedis, NewRedisCacheStore)
+}
+
+// NewRedisCacheStore store of pgo2.Cache plugin based on redis component
+func NewRedisCacheStore(componentId string) iface.ICacheStore {
+	if componentId == "" {
+		componentId = DefaultRedisId
+	}
+
+	client := pgo2.App().Component(componentId, redis.New, map[string]interface{}{"logger": pgo2.GLogger()}).(*redis.Client)
+	return &RedisCacheStore{client: client}
+}
+
+type RedisCacheStore struct {
+	client *redis.Client
+}
+
+func (r *RedisCacheStore) Get(key string) []byte {
+	v, err := r.client.Get(key)
+	if err != nil || v == nil || !v.Valid() {
+		return nil
+	}
+
+	return v.Bytes()
+}
+
+func (r *RedisCacheStore) Set(key string, data []byte, expire time.Duration) bool {
+	ok, err := r.client.Set(key, data, expire)
+	return err == nil && ok
+}
@@ -62,7 +62,7 @@ func (d *Db) Prepare(componentId ...interface{}){
 		id = componentId[0].(string)
 	}
 
-	d.client = pgo2.App().Component(id, db.New).(*db.Client)
+	d.client = pgo2.ContextComponent(d.Context(), id, db.New).(*db.Client)
 }
 
 func (d *Db) SetMaster(v bool) {
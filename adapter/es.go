@@ -42,7 +42,7 @@ func (e *Es) Prepare(componentId ...string) {
     if len(componentId) > 0 {
         id = componentId[0]
     }
-    e.client = pgo2.App().Component(id, es.New).(*es.Client)
+    e.client = pgo2.ContextComponent(e.Context(), id, es.New).(*es.Client)
 }
 
 func (e *Es) GetClient() *es.Client {
@@ -56,7 +56,7 @@ func (h *Http) Prepare(componentId ...interface{}) {
 		id = componentId[0].(string)
 	}
 
-	h.client = pgo2.App().Component(id, phttp.New).(*phttp.Client)
+	h.client = pgo2.ContextComponent(h.Context(), id, phttp.New).(*phttp.Client)
 	h.panicRecover = true
 }
 
@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/pinguo/pgo2"
+	"github.com/pinguo/pgo2/client/db"
+	"github.com/pinguo/pgo2/client/memcache"
+	"github.com/pinguo/pgo2/client/mongo"
+	"github.com/pinguo/pgo2/client/mongodb"
+	"github.com/pinguo/pgo2/client/rabbitmq"
+	"github.com/pinguo/pgo2/client/redis"
+	"github.com/pinguo/pgo2/value"
+)
+
+// key to probe cache servers
+const pingKey = "pgo2:ping"
+
+// register kinds of components for built-in commands, eg. --cmd=/pgo2/component/ping
+func init() {
+	pgo2.RegisterComponentKind(DefaultRedisId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			_, err := redisClient(id).Exists(pingKey)
+			return err
+		},
+		Get: func(id, key string) (interface{}, error) {
+			return cacheValue(redisClient(id).Get(key))
+		},
+		Del: func(id, key string) (bool, error) {
+			return redisClient(id).Del(key)
+		},
+		Lock: redisLock,
+	})
+
+	pgo2.RegisterComponentKind(DefaultMemCacheId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			_, err := memCacheClient(id).Exists(pingKey)
+			return err
+		},
+		Get: func(id, key string) (interface{}, error) {
+			return cacheValue(memCacheClient(id).Get(key))
+		},
+		Del: func(id, key string) (bool, error) {
+			return memCacheClient(id).Del(key)
+		},
+	})
+
+	pgo2.RegisterComponentKind(DefaultDbId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			return pgo2.App().Component(id, db.New).(*db.Client).GetDb(true).Ping()
+		},
+	})
+
+	pgo2.RegisterComponentKind(DefaultMongoId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			session := pgo2.App().Component(id, mongo.New).(*mongo.Client).GetSession()
+			defer session.Close()
+			return session.Ping()
+		},
+	})
+
+	pgo2.RegisterComponentKind(DefaultMongodbId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			client := pgo2.App().Component(id, mongodb.New).(*mongodb.Client)
+			return client.MClient.Ping(int64(client.ReadTimeout().Seconds()) + 1)
+		},
+	})
+
+	pgo2.RegisterComponentKind(DefaultRabbitId, &pgo2.ComponentKind{
+		Ping: func(id string) error {
+			client := pgo2.App().Component(id, rabbitmq.New, map[string]interface{}{"logger": pgo2.GLogger()}).(*rabbitmq.Client)
+			ch, err := client.FreeChannel()
+			if err != nil {
+				return err
+			}
+
+			return ch.Close(false)
+		},
+	})
+}
+
+// redisLock distributed lock of redis for scheduler, slot is recorded
+// in hash of lock when acquired, see pgo2.ComponentLock
+func redisLock(ctx context.Context, id, name string, slot time.Time, ttl time.Duration) (context.Context, int64, func(time.Duration), error) {
+	lock := NewLock(name, ttl, id)
+	held, token, err := lock.Hold(ctx)
+	if err == ErrLockHeld {
+		return nil, 0, nil, nil
+	} else if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// the slot has been run by others
+	ms := slot.UnixNano() / int64(time.Millisecond)
+	if last, e := lock.runSlot(ms); e != nil || last >= ms {
+		lock.Release()
+		return nil, 0, nil, e
+	}
+
+	release := func(keep time.Duration) {
+		if e := lock.Release(keep); e != nil {
+			pgo2.GLogger().Warn("lock " + name + " release failed, err:" + e.Error())
+		}
+	}
+
+	return held, token, release, nil
+}
+
+func redisClient(id string) *redis.Client {
+	return pgo2.App().Component(id, redis.New, map[string]interface{}{"logger": pgo2.GLogger()}).(*redis.Client)
+}
+
+func memCacheClient(id string) *memcache.Client {
+	return pgo2.App().Component(id, memcache.New).(*memcache.Client)
+}
+
+// cacheValue convert cache value to string, nil if not found
+func cacheValue(v *value.Value, err error) (interface{}, error) {
+	if err != nil || v == nil || !v.Valid() {
+		return nil, err
+	}
+
+	return v.String(), nil
+}
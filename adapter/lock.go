@@ -0,0 +1,287 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pinguo/pgo2"
+	"github.com/pinguo/pgo2/client/redis"
+	"github.com/pinguo/pgo2/util"
+)
+
+var (
+	ErrLockHeld = errors.New("lock: held by others")
+	ErrLockLost = errors.New("lock: lease lost")
+)
+
+// lock is a hash of owner, expire time(ms), fencing token and the last run
+// slot(ms) of scheduler, the hash is kept after release, so fencing token
+// keeps increasing and the last slot is kept for the lock.
+const (
+	lockKeyPrefix = "pgo2:lock:"
+
+	// KEYS[1]: key, ARGV[1]: owner, ARGV[2]: ttl(ms)
+	// return fencing token, 0 if held by others
+	lockAcquireScript = `redis.replicate_commands()
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local v = redis.call('HMGET', KEYS[1], 'owner', 'expire', 'fence')
+local alive = v[1] and tonumber(v[2]) > now
+if alive and v[1] ~= ARGV[1] then
+    return 0
+end
+local fence = tonumber(v[3]) or 0
+if not alive then
+    fence = redis.call('HINCRBY', KEYS[1], 'fence', 1)
+end
+redis.call('HMSET', KEYS[1], 'owner', ARGV[1], 'expire', now + tonumber(ARGV[2]))
+return fence`
+
+	// KEYS[1]: key, ARGV[1]: owner, ARGV[2]: ttl(ms)
+	// return fencing token, 0 if lost
+	lockRenewScript = `redis.replicate_commands()
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local v = redis.call('HMGET', KEYS[1], 'owner', 'expire', 'fence')
+if v[1] ~= ARGV[1] or tonumber(v[2]) <= now then
+    return 0
+end
+redis.call('HSET', KEYS[1], 'expire', now + tonumber(ARGV[2]))
+return tonumber(v[3])`
+
+	// KEYS[1]: key, ARGV[1]: owner, ARGV[2]: keep(ms)
+	// lease is kept for keep ms if keep > 0, return 1 if released
+	lockReleaseScript = `redis.replicate_commands()
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+    return 0
+end
+if tonumber(ARGV[2]) > 0 then
+    local t = redis.call('TIME')
+    redis.call('HSET', KEYS[1], 'expire', t[1] * 1000 + math.floor(t[2] / 1000) + tonumber(ARGV[2]))
+else
+    redis.call('HDEL', KEYS[1], 'owner', 'expire')
+end
+return 1`
+
+	// KEYS[1]: key, ARGV[1]: owner, ARGV[2]: slot(ms)
+	// slot is recorded if it's newer, return the last slot, -1 if not held
+	lockSlotScript = `if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+    return -1
+end
+local last = tonumber(redis.call('HGET', KEYS[1], 'slot')) or 0
+if tonumber(ARGV[2]) > last then
+    redis.call('HSET', KEYS[1], 'slot', ARGV[2])
+end
+return last`
+)
+
+// NewLock distributed lock based on redis with lease renewal and fencing
+// token, it is not reentrant across goroutines, require redis-server 3.2+.
+// usage: adapter.NewLock("billing", 30*time.Second).Elect(ctx, func(ctx context.Context, token int64) {...})
+func NewLock(name string, ttl time.Duration, componentId ...string) *Lock {
+	id := DefaultRedisId
+	if len(componentId) > 0 {
+		id = componentId[0]
+	}
+
+	if ttl < time.Second {
+		panic("Lock: ttl must be at least 1s, " + ttl.String())
+	}
+
+	hostname, _ := os.Hostname()
+	return &Lock{
+		client: redisClient(id),
+		name:   name,
+		owner:  fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), util.GenUniqueId()),
+		ttl:    ttl,
+	}
+}
+
+type Lock struct {
+	client *redis.Client
+	name   string
+	owner  string
+	ttl    time.Duration
+
+	lock   sync.Mutex
+	token  int64
+	cancel context.CancelFunc
+}
+
+// Name get name of lock
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// Token get fencing token of current lease, 0 if not held,
+// token increases every time the lock is acquired by anyone.
+func (l *Lock) Token() int64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.token
+}
+
+// Acquire acquire lock with a lease of ttl, fencing token is returned,
+// ErrLockHeld is returned if the lock is held by others.
+func (l *Lock) Acquire() (int64, error) {
+	token, err := l.eval(lockAcquireScript, l.ttl)
+	if err != nil {
+		return 0, err
+	}
+
+	if token == 0 {
+		return 0, ErrLockHeld
+	}
+
+	l.lock.Lock()
+	l.token = token
+	l.lock.Unlock()
+
+	return token, nil
+}
+
+// Renew extend lease of lock to ttl, ErrLockLost is returned if
+// lease has expired or the lock is acquired by others.
+func (l *Lock) Renew() error {
+	token, err := l.eval(lockRenewScript, l.ttl)
+	if err != nil {
+		return err
+	}
+
+	if token == 0 {
+		l.lock.Lock()
+		l.token = 0
+		l.lock.Unlock()
+
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Release stop renewal and release lock, the lease is kept for
+// keep duration if specified, eg. avoid rerun by other replicas.
+func (l *Lock) Release(keep ...time.Duration) error {
+	l.lock.Lock()
+	if l.cancel != nil {
+		l.cancel()
+		l.cancel = nil
+	}
+	l.token = 0
+	l.lock.Unlock()
+
+	var d time.Duration
+	if len(keep) > 0 {
+		d = keep[0]
+	}
+
+	_, err := l.eval(lockReleaseScript, d)
+	return err
+}
+
+// Hold acquire lock and renew lease in background, the returned context
+// is cancelled when lease is lost, ctx is done or lock is released.
+func (l *Lock) Hold(ctx context.Context) (context.Context, int64, error) {
+	token, err := l.Acquire()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	held, cancel := context.WithCancel(ctx)
+
+	l.lock.Lock()
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.cancel = cancel
+	l.lock.Unlock()
+
+	go l.keepAlive(held, cancel)
+
+	return held, token, nil
+}
+
+// keepAlive renew lease every third of ttl, lease is regarded as lost if
+// renewal failed for two thirds of ttl, so it's cancelled before expiration.
+func (l *Lock) keepAlive(held context.Context, cancel context.CancelFunc) {
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-held.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := l.Renew()
+		if err == nil {
+			renewed = time.Now()
+			continue
+		}
+
+		if err == ErrLockLost || time.Since(renewed) >= l.ttl-interval {
+			pgo2.GLogger().Warn("lock " + l.name + " lost, err:" + err.Error())
+			cancel()
+			return
+		}
+
+		pgo2.GLogger().Warn("lock " + l.name + " renew failed, err:" + err.Error())
+	}
+}
+
+// Elect campaign for leadership until ctx is done, fn is called with a
+// context which is cancelled on leader loss, it campaigns again after fn
+// returns, fencing token of the leadership is passed to fn.
+func (l *Lock) Elect(ctx context.Context, fn func(ctx context.Context, token int64)) {
+	for {
+		held, token, err := l.Hold(ctx)
+		if err == nil {
+			fn(held, token)
+			if e := l.Release(); e != nil {
+				pgo2.GLogger().Warn("lock " + l.name + " release failed, err:" + e.Error())
+			}
+		} else if err != ErrLockHeld {
+			pgo2.GLogger().Warn("lock " + l.name + " acquire failed, err:" + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.ttl / 3):
+		}
+	}
+}
+
+// runSlot record slot(ms) as run while lock is held, the last recorded
+// slot is returned, so the same slot is not run again by others.
+func (l *Lock) runSlot(slot int64) (int64, error) {
+	ret, err := l.client.Eval(lockSlotScript, lockKeyPrefix+l.name, l.owner, slot)
+	if err != nil {
+		return 0, err
+	}
+
+	last, _ := ret.(int64)
+	if last < 0 {
+		return 0, ErrLockLost
+	}
+
+	return last, nil
+}
+
+func (l *Lock) eval(script string, d time.Duration) (int64, error) {
+	ret, err := l.client.Eval(script, lockKeyPrefix+l.name, l.owner, int64(d/time.Millisecond))
+	if err != nil {
+		return 0, err
+	}
+
+	token, _ := ret.(int64)
+	return token, nil
+}
@@ -49,7 +49,7 @@ func (m *MaxMind) Prepare(componentId ...interface{}) {
 		id = componentId[0].(string)
 	}
 
-	m.client = pgo2.App().Component(id, maxmind.New).(*maxmind.Client)
+	m.client = pgo2.ContextComponent(m.Context(), id, maxmind.New).(*maxmind.Client)
 }
 
 func (m *MaxMind) GetClient() *maxmind.Client {
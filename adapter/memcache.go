@@ -52,7 +52,7 @@ func (m *MemCache) Prepare(componentId ...interface{}) {
 		id = componentId[0].(string)
 	}
 
-	m.client = pgo2.App().Component(id, memcache.New).(*memcache.Client)
+	m.client = pgo2.ContextComponent(m.Context(), id, memcache.New).(*memcache.Client)
 }
 
 func (m *MemCache) SetPanicRecover(v bool) {
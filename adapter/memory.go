@@ -54,7 +54,7 @@ func (m *Memory) Prepare(componentId ...interface{}){
 		id = componentId[0].(string)
 	}
 
-	m.client = pgo2.App().Component(id, memory.New, map[string]interface{}{"logger":pgo2.GLogger()}).(*memory.Client)
+	m.client = pgo2.ContextComponent(m.Context(), id, memory.New, map[string]interface{}{"logger":pgo2.GLogger()}).(*memory.Client)
 	m.panicRecover = true
 }
 
@@ -70,7 +70,7 @@ func (m *Mongo) Prepare(args ...interface{}) {
 	}
 
 
-	m.client = pgo2.App().Component(id, mongo.New).(*mongo.Client)
+	m.client = pgo2.ContextComponent(m.Context(), id, mongo.New).(*mongo.Client)
 	m.db = db
 	m.coll = coll
 
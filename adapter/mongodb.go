@@ -68,7 +68,7 @@ func (m *Mongodb) Prepare(db, coll string, componentId ...string) {
 		}
 	}
 
-	m.client = pgo2.App().Component(id, mongodb.New).(*mongodb.Client)
+	m.client = pgo2.ContextComponent(m.Context(), id, mongodb.New).(*mongodb.Client)
 	m.db = db
 	m.coll = coll
 
@@ -39,7 +39,7 @@ func NewOrm(ctr iface.IContext, componentId ...string) *Orm {
 		id = componentId[0]
 	}
 	o := &Orm{}
-	o.client = pgo2.App().Component(id, orm.New).(*orm.Client)
+	o.client = pgo2.ContextComponent(ctr, id, orm.New).(*orm.Client)
 	o.componentId = id
 	o.DB = o.dbSession(ctr)
 
@@ -61,7 +61,7 @@ func (o *Orm) Prepare(componentId ...interface{}) {
 		id = componentId[0].(string)
 	}
 	o.componentId = id
-	o.client = pgo2.App().Component(id, orm.New).(*orm.Client)
+	o.client = pgo2.ContextComponent(o.Context(), id, orm.New).(*orm.Client)
 	if len(componentId) < 2 {
 		o.DB = o.dbSession(o.Context())
 	}
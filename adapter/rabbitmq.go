@@ -70,7 +70,7 @@ func (r *RabbitMq) Prepare(dftConfig ...interface{}) {
 		op["pass"] = dftConfig[2]
 	}
 
-	r.client = pgo2.App().Component(id, rabbitmq.New, op).(*rabbitmq.Client)
+	r.client = pgo2.ContextComponent(r.Context(), id, rabbitmq.New, op).(*rabbitmq.Client)
 	r.panicRecover = true
 
 }
@@ -52,7 +52,7 @@ func (r *Redis) Prepare(componentId ...interface{}) {
 		id = componentId[0].(string)
 	}
 
-	r.client = pgo2.App().Component(id, redis.New, map[string]interface{}{"logger":pgo2.GLogger()}).(*redis.Client)
+	r.client = pgo2.ContextComponent(r.Context(), id, redis.New, map[string]interface{}{"logger":pgo2.GLogger()}).(*redis.Client)
 	r.panicRecover = true
 
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"runtime/debug"
 	"strings"
 	"sync"
 
@@ -57,6 +58,7 @@ type Application struct {
 	container  *Container
 	server     *Server
 	router     *Router
+	scheduler  *Scheduler
 	log        *logs.Log
 	status     iface.IStatus
 	i18n       iface.II18n
@@ -65,6 +67,7 @@ type Application struct {
 
 	components map[string]interface{}
 	objects    map[string]iface.IObject
+	modules    map[string]*Module
 	lock       sync.RWMutex
 
 	args map[string]string
@@ -150,6 +153,7 @@ func (app *Application) Init(osArgs []string) {
 	// initialize container object
 	enablePool, _ := app.config.Get("app.container.enablePool").(string)
 	app.container = NewContainer(enablePool)
+	app.container.SetAppPkg(app.config.GetString("app.container.appPkg", mainModulePath()))
 
 	// initialize server object
 	svrConf, _ := app.config.Get("app.server").(map[string]interface{})
@@ -188,6 +192,15 @@ func (app *Application) Init(osArgs []string) {
 	}
 }
 
+// mainModulePath get module path of main package, eg. github.com/foo/bar
+func mainModulePath() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+
+	return ""
+}
+
 func (app *Application) genBasePath(exeDir string) string {
 	basePath, _ := filepath.Abs(filepath.Join(exeDir, ".."))
 
@@ -258,6 +271,15 @@ func (app *Application) Router() *Router {
 	return app.router
 }
 
+// Scheduler  scheduler component
+func (app *Application) Scheduler() *Scheduler {
+	if app.scheduler == nil {
+		app.scheduler = NewScheduler(app.componentConf("scheduler"))
+	}
+
+	return app.scheduler
+}
+
 // Log  log component
 func (app *Application) Log() *logs.Log {
 	if app.log == nil {
@@ -329,10 +351,14 @@ func (app *Application) componentConf(id string) map[string]interface{} {
 		return nil
 	}
 
-	if retConf, ok := conf.(map[string]interface{}); ok {
-		for k, v := range retConf {
+	if conf, ok := conf.(map[string]interface{}); ok {
+		// copy conf, params of component are merged into it
+		retConf := make(map[string]interface{}, len(conf))
+		for k, v := range conf {
 			if vv, ok := v.(string); ok == true {
 				retConf[k] = GetAlias(vv)
+			} else {
+				retConf[k] = v
 			}
 		}
 
@@ -370,6 +396,30 @@ func (app *Application) Component(id string, funcName iface.IComponentFunc, para
 	return app.components[id]
 }
 
+// Module get module by name, module is created on first use
+func (app *Application) Module(name string) *Module {
+	app.lock.RLock()
+	module, ok := app.modules[name]
+	app.lock.RUnlock()
+	if ok {
+		return module
+	}
+
+	app.lock.Lock()
+	defer app.lock.Unlock()
+
+	if module, ok = app.modules[name]; !ok {
+		if app.modules == nil {
+			app.modules = make(map[string]*Module)
+		}
+
+		module = newModule(app, name)
+		app.modules[name] = module
+	}
+
+	return module
+}
+
 // Get get pool class object. name is class name, ctx is context,
 func (app *Application) GetObjPool(name string, ctx iface.IContext, params ...interface{}) iface.IObject {
 	if name := GetAlias(name); len(name) > 0 {
@@ -0,0 +1,370 @@
+package pgo2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pinguo/pgo2/logs"
+	"github.com/pinguo/pgo2/util"
+)
+
+// BuiltinCmdPrefix reserved cmd namespace of built-in commands
+const BuiltinCmdPrefix = "/pgo2/"
+
+// descriptions of built-in commands for --help, [desc, params]
+var builtinCommandDesc = map[string][2]string{
+	"/pgo2/config/check":    {"check config path and parse all config files", ""},
+	"/pgo2/config/dump":     {"dump config with secrets masked", "    \t  --key string    \tdot separated config key, eg. app.components"},
+	"/pgo2/routes/index":    {"list web routes", "    \t  --format string    \ttext or json (default text)"},
+	"/pgo2/scheduler/index": {"run scheduled jobs until SIGINT/SIGTERM", ""},
+	"/pgo2/scheduler/list":  {"list scheduled jobs and next run time", ""},
+	"/pgo2/component/ping":  {"test connectivity of configured components", "    \t  --id string    \tcomponent id, default all"},
+	"/pgo2/cache/get":       {"inspect cache key", "    \t  --id string    \tcache component id, eg. redis\n    \t  --key string    \tcache key"},
+	"/pgo2/cache/del":       {"delete cache key", "    \t  --id string    \tcache component id, eg. redis\n    \t  --key string    \tcache key"},
+	"/pgo2/log/level":       {"show or change log levels of running server by debug server", "    \t  --levels string    \tnew levels, eg. WARN,ERROR,FATAL\n    \t  --addr string    \tdebug address of server (default app.server.debugAddr)"},
+}
+
+// bindBuiltinCommands bind built-in commands under reserved namespace,
+// commands of application under command/pgo2 are overridden.
+func bindBuiltinCommands(container *Container) {
+	pkg := ControllerCmdPkg + "/pgo2/"
+	container.bind(&configCommand{}, pkg+"ConfigCommand")
+	container.bind(&routesCommand{}, pkg+"RoutesCommand")
+	container.bind(&schedulerCommand{}, pkg+"SchedulerCommand")
+	container.bind(&componentCommand{}, pkg+"ComponentCommand")
+	container.bind(&cacheCommand{}, pkg+"CacheCommand")
+	container.bind(&logCommand{}, pkg+"LogCommand")
+}
+
+// requireArg get required arg of command
+func requireArg(name string) string {
+	v := App().Arg(name)
+	if v == "" {
+		panic("--" + name + " is required")
+	}
+
+	return v
+}
+
+type configCommand struct {
+	Controller
+}
+
+// ActionCheck check config path and parse all config files
+func (c *configCommand) ActionCheck() {
+	if err := App().Config().CheckPath(); err != nil {
+		panic(err.Error())
+	}
+
+	confPath := filepath.Join(App().BasePath(), "configs")
+	paths := []string{confPath}
+	if App().Env() != "" {
+		paths = append(paths, filepath.Join(confPath, App().Env()))
+	}
+
+	failed := 0
+	for _, path := range paths {
+		files, _ := filepath.Glob(filepath.Join(path, "*.*"))
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			if e := checkConfig(name); e != "" {
+				failed++
+				fmt.Printf("%s: failed, %s\n", file, e)
+				continue
+			}
+
+			fmt.Printf("%s: ok\n", file)
+		}
+	}
+
+	if failed > 0 {
+		panic(fmt.Sprintf("%d config files are invalid", failed))
+	}
+}
+
+// checkConfig load config by name, error message is returned
+func checkConfig(name string) (err string) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = util.ToString(v)
+		}
+	}()
+
+	App().Config().Get(name)
+	return ""
+}
+
+// ActionDump dump config with secrets masked
+func (c *configCommand) ActionDump() {
+	key := App().Arg("key")
+	if key == "" {
+		key = "app"
+	}
+
+	v := App().Config().Get(key)
+	if v == nil {
+		panic("config not found, " + key)
+	}
+
+	data, e := json.MarshalIndent(maskConfig("", v), "", "  ")
+	if e != nil {
+		panic(e.Error())
+	}
+
+	fmt.Println(string(data))
+}
+
+var (
+	reSecretKey = regexp.MustCompile(`(?i)pass|secret|token|credential`)
+	reDsnSecret = regexp.MustCompile(`(://[^:/@]*:)[^@/]*@`)
+)
+
+// maskConfig mask value of secret keys and password in dsn
+func maskConfig(key string, v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(vv))
+		for k, item := range vv {
+			ret[k] = maskConfig(k, item)
+		}
+		return ret
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(vv))
+		for k, item := range vv {
+			ks := util.ToString(k)
+			ret[ks] = maskConfig(ks, item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(vv))
+		for i, item := range vv {
+			ret[i] = maskConfig(key, item)
+		}
+		return ret
+	case string:
+		if reSecretKey.MatchString(key) {
+			return "******"
+		}
+		return reDsnSecret.ReplaceAllString(vv, "${1}******@")
+	}
+
+	return v
+}
+
+type routesCommand struct {
+	Controller
+}
+
+// ActionIndex list web routes
+func (c *routesCommand) ActionIndex() {
+	printRoutes(App().Arg("format"))
+}
+
+type schedulerCommand struct {
+	Controller
+}
+
+// ActionIndex run scheduled jobs until SIGINT/SIGTERM
+func (c *schedulerCommand) ActionIndex() {
+	scheduler := App().Scheduler()
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	select {
+	case v := <-sig:
+		GLogger().Info("scheduler receive signal " + v.String() + ", stop scheduler")
+	case <-c.Context().GoContext().Done():
+	}
+}
+
+// ActionList list scheduled jobs and next run time
+func (c *schedulerCommand) ActionList() {
+	for _, job := range App().Scheduler().Jobs() {
+		fmt.Printf("%s\t%s\t%s\toverlap=%s jitter=%s\tnext=%s\n", job.Name, job.Cmd, job.Spec, job.Overlap, job.Jitter, job.Next.Format(time.RFC3339))
+	}
+}
+
+type componentCommand struct {
+	Controller
+}
+
+// ActionPing test connectivity of configured components
+func (c *componentCommand) ActionPing() {
+	ids := []string{App().Arg("id")}
+	if ids[0] == "" {
+		ids = configuredComponents()
+	}
+
+	failed := 0
+	for _, id := range ids {
+		name, kind := componentKind(id)
+		if kind == nil {
+			fmt.Printf("%s: skipped, unknown kind\n", id)
+			continue
+		}
+
+		start := time.Now()
+		if e := pingComponent(kind, id); e != nil {
+			failed++
+			fmt.Printf("%s(%s): failed, %s\n", id, name, e)
+			continue
+		}
+
+		fmt.Printf("%s(%s): ok, %s\n", id, name, time.Since(start))
+	}
+
+	if failed > 0 {
+		panic(fmt.Sprintf("%d components are unreachable", failed))
+	}
+}
+
+// pingComponent ping component, panic is converted to error
+func pingComponent(kind *ComponentKind, id string) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%s", util.ToString(v))
+		}
+	}()
+
+	return kind.Ping(id)
+}
+
+// configuredComponents sorted ids of components in config
+func configuredComponents() []string {
+	var ids []string
+	switch conf := App().Config().Get("app.components").(type) {
+	case map[string]interface{}:
+		for id := range conf {
+			ids = append(ids, id)
+		}
+	case map[interface{}]interface{}:
+		for id := range conf {
+			ids = append(ids, util.ToString(id))
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+type cacheCommand struct {
+	Controller
+}
+
+// cacheKind get cache kind of component
+func (c *cacheCommand) cacheKind(id string) *ComponentKind {
+	name, kind := componentKind(id)
+	if kind == nil || kind.Get == nil || kind.Del == nil {
+		panic("component is not cache, " + id + "(" + name + ")")
+	}
+
+	return kind
+}
+
+// ActionGet inspect cache key
+func (c *cacheCommand) ActionGet() {
+	id, key := requireArg("id"), requireArg("key")
+	v, e := c.cacheKind(id).Get(id, key)
+	if e != nil {
+		panic(e.Error())
+	}
+
+	if v == nil {
+		fmt.Printf("%s: not found\n", key)
+		return
+	}
+
+	fmt.Printf("%s: %s\n", key, util.ToString(v))
+}
+
+// ActionDel delete cache key
+func (c *cacheCommand) ActionDel() {
+	id, key := requireArg("id"), requireArg("key")
+	ok, e := c.cacheKind(id).Del(id, key)
+	if e != nil {
+		panic(e.Error())
+	}
+
+	fmt.Printf("%s: deleted=%v\n", key, ok)
+}
+
+type logCommand struct {
+	Controller
+}
+
+// ActionLevel show or change log levels of running server
+func (c *logCommand) ActionLevel() {
+	addr := App().Arg("addr")
+	if addr == "" {
+		addr = App().Config().GetString("app.server.debugAddr", "")
+	}
+
+	if addr == "" {
+		panic("--addr is required, debugAddr of server is not configured")
+	}
+
+	u := "http://" + strings.Replace(addr, "0.0.0.0", "127.0.0.1", 1) + "/log/level"
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	var rsp *http.Response
+	var e error
+	if levels := App().Arg("levels"); levels != "" {
+		rsp, e = client.PostForm(u, url.Values{"levels": {levels}})
+	} else {
+		rsp, e = client.Get(u)
+	}
+
+	if e != nil {
+		panic(e.Error())
+	}
+
+	defer rsp.Body.Close()
+	body, _ := ioutil.ReadAll(rsp.Body)
+	if rsp.StatusCode != http.StatusOK {
+		panic(fmt.Sprintf("status %d, %s", rsp.StatusCode, body))
+	}
+
+	fmt.Printf("log levels: %s\n", body)
+}
+
+// handleLogLevel show or change log levels on debug server
+func handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		levels := r.FormValue("levels")
+		if e := setLogLevels(levels); e != "" {
+			http.Error(w, e, http.StatusBadRequest)
+			return
+		}
+
+		GLogger().Info("log levels changed to " + levels)
+	}
+
+	w.Write([]byte(logs.LevelsToString(App().Log().Levels())))
+}
+
+// setLogLevels set levels of log, error message is returned
+func setLogLevels(levels string) (err string) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = util.ToString(v)
+		}
+	}()
+
+	App().Log().SetLevels(levels)
+	return ""
+}
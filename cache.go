@@ -0,0 +1,528 @@
+package pgo2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinguo/pgo2/client/memory"
+	"github.com/pinguo/pgo2/core"
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/util"
+)
+
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+
+	CacheStateHit   = "HIT"
+	CacheStateStale = "STALE"
+	CacheStateMiss  = "MISS"
+)
+
+// CacheStoreFunc create cache store of backend by component id
+type CacheStoreFunc func(componentId string) iface.ICacheStore
+
+// memory backend is built in, redis backend is registered by adapter package
+var cacheStores = map[string]CacheStoreFunc{
+	CacheBackendMemory: newMemoryCacheStore,
+}
+
+// RegisterCacheStore register store backend of Cache plugin
+func RegisterCacheStore(backend string, fn CacheStoreFunc) {
+	cacheStores[backend] = fn
+}
+
+// headers not stored in cache entry
+var cacheSkipHeaders = map[string]bool{
+	"X-Log-Id": true, "X-Cost-Time": true, "X-Cache": true, "Age": true,
+	"Content-Length": true, "Connection": true, "Set-Cookie": true,
+}
+
+// Cache http response cache plugin, 200 response of GET and HEAD request
+// matched by route is stored in backend, response with Set-Cookie header
+// or "Cache-Control: no-store/private" is not cached. concurrent requests
+// of the same missing entry are coalesced, only one of them calls action.
+// redis backend requires importing the adapter package, configuration:
+// server:
+//     plugins: ["cache"]
+//     cache:
+//         backend: "memory"      // memory or redis
+//         componentId: "memory"  // component id of backend, default is backend name
+//         prefix: "pgo2_cache_"
+//         maxSize: 1048576       // max body size to cache
+//         waitTimeout: "5s"      // max time waiting for the coalesced request
+//         routes:
+//             - path: "/api/config"           // exact path, or path prefix ended with *
+//               ttl: "60s"
+//               stale: "30s"                  // serve stale entry while revalidating in background
+//               varyQuery: ["version"]        // "*" for all query params
+//               varyHeader: ["X-Platform"]
+//               varyLang: true                // vary by client language
+func NewCache(config map[string]interface{}) *Cache {
+	cache := &Cache{
+		backend:     CacheBackendMemory,
+		prefix:      "pgo2_cache_",
+		maxSize:     1 << 20,
+		waitTimeout: 5 * time.Second,
+		calls:       make(map[string]*cacheCall),
+	}
+
+	cache.init(config)
+
+	return cache
+}
+
+type Cache struct {
+	backend     string
+	componentId string
+	prefix      string
+	maxSize     int
+	waitTimeout time.Duration
+	rules       []*cacheRule
+	store       iface.ICacheStore
+
+	lock  sync.Mutex
+	calls map[string]*cacheCall
+}
+
+type cacheRule struct {
+	path       string
+	prefix     bool
+	ttl        time.Duration
+	stale      time.Duration
+	varyQuery  []string
+	varyHeader []string
+	varyLang   bool
+}
+
+type cacheEntry struct {
+	Header http.Header `json:"h"`
+	Body   []byte      `json:"b"`
+	Time   int64       `json:"t"` // unix nano of storing
+	Fresh  int64       `json:"f"` // unix nano of expiration
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	entry *cacheEntry
+}
+
+// SetBackend set store backend, memory or redis
+func (c *Cache) SetBackend(v string) {
+	c.backend = v
+}
+
+// SetComponentId set component id of backend
+func (c *Cache) SetComponentId(v string) {
+	c.componentId = v
+}
+
+// SetPrefix set key prefix
+func (c *Cache) SetPrefix(v string) {
+	c.prefix = v
+}
+
+// SetMaxSize set max body size to cache
+func (c *Cache) SetMaxSize(v int) {
+	c.maxSize = v
+}
+
+// SetWaitTimeout set max time waiting for the coalesced request
+func (c *Cache) SetWaitTimeout(v string) {
+	c.waitTimeout = c.parseDuration("waitTimeout", v)
+}
+
+// SetStore set store directly instead of backend
+func (c *Cache) SetStore(store iface.ICacheStore) {
+	c.store = store
+}
+
+// SetRoutes set cached routes
+func (c *Cache) SetRoutes(v []interface{}) {
+	for _, vv := range v {
+		conf, ok := vv.(map[string]interface{})
+		if !ok {
+			panic("Cache: invalid route, " + util.ToString(vv))
+		}
+
+		c.AddRoute(conf)
+	}
+}
+
+// AddRoute add one cached route, see NewCache for options
+func (c *Cache) AddRoute(conf map[string]interface{}) {
+	rule := &cacheRule{}
+	rule.path = strings.ToLower(util.ToString(conf["path"]))
+	if rule.path == "" {
+		panic("Cache: empty route path")
+	}
+
+	if strings.HasSuffix(rule.path, "*") {
+		rule.path, rule.prefix = strings.TrimSuffix(rule.path, "*"), true
+	}
+
+	rule.ttl = c.parseDuration("ttl", util.ToString(conf["ttl"]))
+	if rule.ttl <= 0 {
+		panic("Cache: ttl is required, " + rule.path)
+	}
+
+	if v, ok := conf["stale"]; ok {
+		rule.stale = c.parseDuration("stale", util.ToString(v))
+	}
+
+	if v, ok := conf["varyLang"]; ok {
+		rule.varyLang = util.ToBool(v)
+	}
+
+	rule.varyQuery = c.toStrings(conf["varyQuery"])
+	rule.varyHeader = c.toStrings(conf["varyHeader"])
+	c.rules = append(c.rules, rule)
+}
+
+func (c *Cache) parseDuration(name, v string) time.Duration {
+	d, e := time.ParseDuration(v)
+	if e != nil {
+		panic("Cache: invalid " + name + ", " + e.Error())
+	}
+
+	return d
+}
+
+func (c *Cache) toStrings(v interface{}) []string {
+	list, _ := v.([]interface{})
+	ret := make([]string, 0, len(list))
+	for _, vv := range list {
+		ret = append(ret, util.ToString(vv))
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+// init configure and create store, panic if backend is
+// invalid, config is loaded from app.server.cache if not provided.
+func (c *Cache) init(config map[string]interface{}) {
+	if config == nil {
+		config, _ = App().Config().Get("app.server.cache").(map[string]interface{})
+	}
+
+	core.Configure(c, config)
+
+	fn, ok := cacheStores[c.backend]
+	if !ok {
+		panic("Cache: unknown backend " + c.backend + ", import adapter package for redis")
+	}
+
+	id := c.componentId
+	if id == "" {
+		id = c.backend
+	}
+
+	c.store = fn(id)
+}
+
+func (c *Cache) HandleRequest(ctx iface.IContext) {
+	method := ctx.Method()
+	if (method != http.MethodGet && method != http.MethodHead) || ctx.Input() == nil {
+		return
+	}
+
+	rule := c.match(ctx.Path())
+	if rule == nil {
+		return
+	}
+
+	key := c.key(rule, ctx)
+	if entry := c.get(key); entry != nil {
+		if time.Now().UnixNano() < entry.Fresh {
+			c.write(ctx, entry, CacheStateHit)
+			return
+		}
+
+		if rule.stale > 0 {
+			c.revalidate(ctx, rule, key)
+			c.write(ctx, entry, CacheStateStale)
+			return
+		}
+	}
+
+	call, leader := c.acquire(key)
+	if !leader {
+		if entry := call.wait(c.waitTimeout); entry != nil {
+			c.write(ctx, entry, CacheStateHit)
+			return
+		}
+
+		// leader response is not cacheable, process by self
+		ctx.SetHeader("X-Cache", CacheStateMiss)
+		return
+	}
+
+	defer c.release(key, call)
+
+	cw := &cacheWrite{ResponseWriter: ctx.Output(), maxSize: c.maxSize}
+	ctx.SetOutput(cw)
+	ctx.SetHeader("X-Cache", CacheStateMiss)
+	ctx.Next()
+
+	if call.entry = c.capture(rule, ctx.Status(), cw); call.entry != nil {
+		c.set(key, rule, call.entry)
+	}
+}
+
+func (c *Cache) match(path string) *cacheRule {
+	path = strings.ToLower(path)
+	for _, rule := range c.rules {
+		if rule.path == path || (rule.prefix && strings.HasPrefix(path, rule.path)) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// key build cache key by path and vary values
+func (c *Cache) key(rule *cacheRule, ctx iface.IContext) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(ctx.Input().Host)
+	buf.WriteString(strings.ToLower(ctx.Path()))
+
+	for _, name := range rule.varyQuery {
+		if name == "*" {
+			buf.WriteString("?" + ctx.Input().URL.Query().Encode())
+			break
+		}
+
+		buf.WriteString("&" + name + "=" + strings.Join(ctx.QueryArray(name), ","))
+	}
+
+	for _, name := range rule.varyHeader {
+		buf.WriteString("\n" + name + ":" + ctx.Header(name, ""))
+	}
+
+	if rule.varyLang {
+		lang := ctx.Header("Accept-Language", "")
+		if pos := strings.IndexAny(lang, ",;"); pos >= 0 {
+			lang = lang[:pos]
+		}
+
+		buf.WriteString("\nlang:" + util.FormatLanguage(strings.TrimSpace(lang)))
+	}
+
+	return c.prefix + util.Md5String(buf.Bytes())
+}
+
+func (c *Cache) get(key string) *cacheEntry {
+	data := c.store.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if e := json.Unmarshal(data, entry); e != nil {
+		return nil
+	}
+
+	return entry
+}
+
+func (c *Cache) set(key string, rule *cacheRule, entry *cacheEntry) {
+	if data, e := json.Marshal(entry); e == nil {
+		c.store.Set(key, data, rule.ttl+rule.stale)
+	}
+}
+
+// capture create cache entry from captured response, nil if not cacheable
+func (c *Cache) capture(rule *cacheRule, status int, cw *cacheWrite) *cacheEntry {
+	if status != http.StatusOK || cw.overflow {
+		return nil
+	}
+
+	header := cw.Header()
+	if len(header["Set-Cookie"]) > 0 {
+		return nil
+	}
+
+	if cc := header.Get("Cache-Control"); strings.Contains(cc, "no-store") || strings.Contains(cc, "private") {
+		return nil
+	}
+
+	entry := &cacheEntry{Header: make(http.Header), Body: cw.buf.Bytes()}
+	for k, v := range header {
+		if !cacheSkipHeaders[k] {
+			entry.Header[k] = append([]string(nil), v...)
+		}
+	}
+
+	now := time.Now()
+	entry.Time, entry.Fresh = now.UnixNano(), now.Add(rule.ttl).UnixNano()
+	return entry
+}
+
+// write response of cache entry and stop plugin chain
+func (c *Cache) write(ctx iface.IContext, entry *cacheEntry, state string) {
+	header := ctx.Output().Header()
+	for k, v := range entry.Header {
+		header[k] = v
+	}
+
+	age := time.Duration(time.Now().UnixNano()-entry.Time) / time.Second
+	ctx.SetHeader("Age", strconv.Itoa(int(age)))
+	ctx.SetHeader("X-Cache", state)
+	ctx.PushLog("cache", state)
+	ctx.End(http.StatusOK, entry.Body)
+	ctx.Abort()
+}
+
+// revalidate process the request again in background with the
+// rest of plugin chain, and replace the stale entry.
+func (c *Cache) revalidate(ctx iface.IContext, rule *cacheRule, key string) {
+	pCtx, ok := ctx.(*Context)
+	if !ok {
+		return
+	}
+
+	call, leader := c.acquire(key)
+	if !leader {
+		return
+	}
+
+	plugins := pCtx.plugins[pCtx.index+1:]
+	r := pCtx.Input().Clone(context.Background())
+	debug, enableAccessLog, accessLogFormat := pCtx.debug, pCtx.enableAccessLog, pCtx.accessLogFormat
+
+	go func() {
+		defer c.release(key, call)
+
+		rec := &cacheRecorder{header: make(http.Header), status: http.StatusOK}
+		cw := &cacheWrite{ResponseWriter: rec, maxSize: c.maxSize}
+
+		bgCtx := &Context{}
+		bgCtx.HttpRW(debug, enableAccessLog, r, cw)
+		bgCtx.SetAccessLogFormat(accessLogFormat)
+		bgCtx.Process(plugins)
+
+		if call.entry = c.capture(rule, rec.status, cw); call.entry != nil {
+			c.set(key, rule, call.entry)
+		}
+	}()
+}
+
+// acquire get the in-flight call of key, leader is true if the call is created
+func (c *Cache) acquire(key string) (call *cacheCall, leader bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if call, ok := c.calls[key]; ok {
+		return call, false
+	}
+
+	call = &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	return call, true
+}
+
+func (c *Cache) release(key string, call *cacheCall) {
+	c.lock.Lock()
+	delete(c.calls, key)
+	c.lock.Unlock()
+
+	close(call.done)
+}
+
+func (c *cacheCall) wait(timeout time.Duration) *cacheEntry {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+		return c.entry
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cacheWrite copy response body written to the underlying writer
+type cacheWrite struct {
+	http.ResponseWriter
+	buf      bytes.Buffer
+	maxSize  int
+	overflow bool
+}
+
+func (c *cacheWrite) copy(n int, data []byte) {
+	if c.overflow {
+		return
+	}
+
+	if c.buf.Len()+n > c.maxSize {
+		c.overflow = true
+		c.buf.Reset()
+		return
+	}
+
+	c.buf.Write(data[:n])
+}
+
+func (c *cacheWrite) Write(data []byte) (int, error) {
+	n, e := c.ResponseWriter.Write(data)
+	c.copy(n, data)
+	return n, e
+}
+
+func (c *cacheWrite) WriteString(data string) (int, error) {
+	return c.Write([]byte(data))
+}
+
+func (c *cacheWrite) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// cacheRecorder response writer of background revalidation
+type cacheRecorder struct {
+	header http.Header
+	status int
+}
+
+func (c *cacheRecorder) Header() http.Header {
+	return c.header
+}
+
+func (c *cacheRecorder) WriteHeader(status int) {
+	c.status = status
+}
+
+func (c *cacheRecorder) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// memoryCacheStore cache store of memory component
+type memoryCacheStore struct {
+	client *memory.Client
+}
+
+func newMemoryCacheStore(componentId string) iface.ICacheStore {
+	client := App().Component(componentId, memory.New, map[string]interface{}{"logger": GLogger()}).(*memory.Client)
+	return &memoryCacheStore{client: client}
+}
+
+func (m *memoryCacheStore) Get(key string) []byte {
+	if v := m.client.Get(key); v.Valid() {
+		return v.Bytes()
+	}
+
+	return nil
+}
+
+func (m *memoryCacheStore) Set(key string, data []byte, expire time.Duration) bool {
+	return m.client.Set(key, data, expire)
+}
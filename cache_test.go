@@ -0,0 +1,200 @@
+package pgo2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/logs"
+)
+
+type mockCacheStore struct {
+	lock  sync.Mutex
+	items map[string][]byte
+}
+
+func (m *mockCacheStore) Get(key string) []byte {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.items[key]
+}
+
+func (m *mockCacheStore) Set(key string, data []byte, expire time.Duration) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.items[key] = data
+	return true
+}
+
+type mockCachePlugin struct {
+	num    int32
+	sleep  time.Duration
+	header map[string]string
+}
+
+func (m *mockCachePlugin) HandleRequest(ctx iface.IContext) {
+	atomic.AddInt32(&m.num, 1)
+	time.Sleep(m.sleep)
+	for k, v := range m.header {
+		ctx.SetHeader(k, v)
+	}
+	ctx.SetHeader("Content-Type", "text/plain")
+	ctx.End(http.StatusOK, []byte("body"))
+}
+
+func newTestCache(route map[string]interface{}) *Cache {
+	cache := NewCache(map[string]interface{}{"prefix": "test_"})
+	cache.SetStore(&mockCacheStore{items: make(map[string][]byte)})
+	cache.AddRoute(route)
+	return cache
+}
+
+func TestNewCache(t *testing.T) {
+	var obj interface{}
+	obj = NewCache(nil)
+	if _, ok := obj.(iface.IPlugin); ok == false {
+		t.FailNow()
+	}
+
+	if obj.(*Cache).store == nil {
+		t.Fatal(`store should be created with plugin`)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`unknown backend should panic when plugin is built`)
+		}
+	}()
+
+	NewCache(map[string]interface{}{"backend": "unknown"})
+}
+
+func TestCache_AddRoute(t *testing.T) {
+	cache := NewCache(nil)
+	cache.SetRoutes([]interface{}{map[string]interface{}{
+		"path": "/API/*", "ttl": "10s", "stale": "5s", "varyLang": true,
+		"varyQuery": []interface{}{"b", "a"},
+	}})
+
+	rule := cache.match("/api/user")
+	if rule == nil || rule.ttl != 10*time.Second || rule.stale != 5*time.Second || rule.varyLang == false {
+		t.FailNow()
+	}
+
+	if rule.varyQuery[0] != "a" {
+		t.Fatal(`rule.varyQuery[0] != "a"`)
+	}
+
+	if cache.match("/web/user") != nil {
+		t.FailNow()
+	}
+
+	t.Run("noTtl", func(t *testing.T) {
+		defer func() {
+			if err := recover(); err != nil {
+				return
+			}
+			t.FailNow()
+		}()
+		cache.AddRoute(map[string]interface{}{"path": "/a"})
+	})
+}
+
+func TestCache_HandleRequest(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+
+	run := func(cache *Cache, plugin *mockCachePlugin, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		context := &Context{}
+		context.HttpRW(false, false, httptest.NewRequest("GET", path, nil), w)
+		context.Process([]iface.IPlugin{cache, plugin})
+		return w
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		cache := newTestCache(map[string]interface{}{"path": "/test", "ttl": "10s", "varyQuery": []interface{}{"v"}})
+		plugin := &mockCachePlugin{}
+
+		if w := run(cache, plugin, "/test?v=1"); w.Header().Get("X-Cache") != CacheStateMiss {
+			t.Fatal("first request should miss")
+		}
+
+		w := run(cache, plugin, "/test?v=1&other=1")
+		if w.Header().Get("X-Cache") != CacheStateHit || w.Body.String() != "body" {
+			t.Fatal("second request should hit")
+		}
+
+		if w.Header().Get("Content-Type") != "text/plain" {
+			t.Fatal("header should be cached")
+		}
+
+		run(cache, plugin, "/test?v=2")
+		if plugin.num != 2 {
+			t.Fatal("plugin.num != 2")
+		}
+	})
+
+	t.Run("setCookie", func(t *testing.T) {
+		cache := newTestCache(map[string]interface{}{"path": "/test", "ttl": "10s"})
+		plugin := &mockCachePlugin{header: map[string]string{"Set-Cookie": "a=1"}}
+		run(cache, plugin, "/test")
+		run(cache, plugin, "/test")
+		if plugin.num != 2 {
+			t.Fatal("plugin.num != 2")
+		}
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		cache := newTestCache(map[string]interface{}{"path": "/test", "ttl": "10s"})
+		plugin := &mockCachePlugin{sleep: 50 * time.Millisecond}
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if w := run(cache, plugin, "/test"); w.Body.String() != "body" {
+					t.Error(`w.Body.String() != "body"`)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if plugin.num != 1 {
+			t.Fatal("plugin.num != 1")
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		cache := newTestCache(map[string]interface{}{"path": "/test", "ttl": "1ms", "stale": "10s"})
+		plugin := &mockCachePlugin{}
+		run(cache, plugin, "/test")
+		time.Sleep(5 * time.Millisecond)
+
+		if w := run(cache, plugin, "/test"); w.Header().Get("X-Cache") != CacheStateStale {
+			t.Fatal("second request should be stale")
+		}
+
+		for i := 0; i < 100 && atomic.LoadInt32(&plugin.num) < 2; i++ {
+			time.Sleep(time.Millisecond)
+		}
+
+		if atomic.LoadInt32(&plugin.num) != 2 {
+			t.Fatal("stale entry should be revalidated")
+		}
+
+		// wait for revalidation goroutine to release the call
+		pending := func() int {
+			cache.lock.Lock()
+			defer cache.lock.Unlock()
+			return len(cache.calls)
+		}
+
+		for i := 0; i < 100 && pending() > 0; i++ {
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
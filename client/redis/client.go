@@ -310,6 +310,20 @@ func (c *Client) Do(cmd string, args ...interface{}) (interface{}, error) {
 	return conn.Do(cmd, args...)
 }
 
+// Eval eval lua script on server of key, key is passed as KEYS[1],
+// args are passed as ARGV, require redis-server 3.2+ if script writes
+// after non-deterministic commands, eg. TIME.
+func (c *Client) Eval(script, key string, args ...interface{}) (interface{}, error) {
+	newKey := c.BuildKey(key)
+	conn, err := c.GetConnByKey("EVAL", newKey)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(false)
+
+	return conn.Do("EVAL", append([]interface{}{script, 1, newKey}, args...)...)
+}
+
 func (c *Client) Expire(key string, expire time.Duration) (bool, error) {
 	newKey := c.BuildKey(key)
 	conn, errConn := c.GetConnByKey("EXPIRE", newKey)
@@ -0,0 +1,22 @@
+package redis
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestConn_ReadReply(t *testing.T) {
+	for reply, want := range map[string]int64{
+		":99\r\n":                  99,
+		":-1\r\n":                  -1,
+		":32768\r\n":               32768,
+		":1600000000000\r\n":       1600000000000,
+		":9223372036854775807\r\n": 9223372036854775807,
+	} {
+		c := &Conn{rw: bufio.NewReadWriter(bufio.NewReader(strings.NewReader(reply)), nil)}
+		if ret, err := c.ReadReply(); err != nil || ret != want {
+			t.Fatalf("read %q failed, ret:%v, err:%v", reply, ret, err)
+		}
+	}
+}
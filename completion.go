@@ -0,0 +1,261 @@
+package pgo2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	builtinParamRe = regexp.MustCompile(`--(\w+)(?: (\S+))?\s+\t([^\n]*)`)
+	funcNameRe     = regexp.MustCompile(`\W`)
+	zshEscaper     = strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`, "\n", " ")
+)
+
+// cmdCompletion command path with description and params for completion
+type cmdCompletion struct {
+	path   string
+	desc   string
+	params []*ActionInfoParam
+}
+
+// cmdCompletions get sorted commands with params, params are taken from
+// args struct, annotations of action or usage of built-in commands.
+func cmdCompletions() []*cmdCompletion {
+	list := make([]*cmdCompletion, 0)
+	for uri, handler := range App().Router().CmdHandlers() {
+		item := &cmdCompletion{path: uri}
+		if desc, ok := builtinCommandDesc[handler.uri]; ok {
+			item.desc = desc[0]
+			for _, m := range builtinParamRe.FindAllStringSubmatch(desc[1], -1) {
+				item.params = append(item.params, &ActionInfoParam{Name: m[1], NameType: m[2], Usage: m[3]})
+			}
+		} else {
+			item.desc, item.params = cmdCompletionParams(handler)
+		}
+
+		list = append(list, item)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].path < list[j].path })
+	return list
+}
+
+// cmdCompletionParams get description and params of command action,
+// annotations are ignored if source of action is failed to parse.
+func cmdCompletionParams(handler *Handler) (desc string, params []*ActionInfoParam) {
+	defer func() {
+		recover()
+	}()
+
+	rv := App().Container().getNew(GetAlias(handler.cPath))
+	if !rv.IsValid() {
+		return
+	}
+
+	method := rv.Type().Method(handler.aId)
+	params = commandArgsParams(rv, method)
+	actionInfo := NewParser().GetActionInfo(rv.Type().Elem().PkgPath(), rv.Type().Elem().Name(), method.Name)
+	if actionInfo == nil {
+		return
+	}
+
+	desc = actionInfo.Desc
+	if params == nil {
+		for _, v := range actionInfo.ParamsDesc {
+			params = append(params, v)
+		}
+
+		sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	}
+
+	return
+}
+
+// globalCompletions get sorted global params
+func globalCompletions() []*ActionInfoParam {
+	params := make([]*ActionInfoParam, 0, len(globalParams))
+	for name, v := range globalParams {
+		params = append(params, &ActionInfoParam{Name: name, NameType: "string", Usage: v.Usage})
+	}
+
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// writeCompletion write completion script of shell to stdout,
+// bash, zsh and fish are supported, eg. --completion=bash
+func writeCompletion(shell string) {
+	name := filepath.Base(os.Args[0])
+	buf := &bytes.Buffer{}
+	switch shell {
+	case "bash":
+		bashCompletion(buf, name)
+	case "zsh":
+		zshCompletion(buf, name)
+	case "fish":
+		fishCompletion(buf, name)
+	default:
+		panic("invalid completion shell, need bash, zsh or fish: " + shell)
+	}
+
+	os.Stdout.Write(buf.Bytes())
+}
+
+// completionFuncName get shell function name of program
+func completionFuncName(name string) string {
+	return "_pgo2_" + funcNameRe.ReplaceAllString(name, "_")
+}
+
+// flagWords get words of params for completion, eg. --env= --help
+func flagWords(params []*ActionInfoParam) string {
+	words := make([]string, 0, len(params))
+	for _, v := range params {
+		if v.NameType == "" {
+			words = append(words, "--"+v.Name)
+		} else {
+			words = append(words, "--"+v.Name+"=")
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func bashCompletion(w io.Writer, name string) {
+	fn := completionFuncName(name)
+	cmds := cmdCompletions()
+	paths := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		paths = append(paths, c.path)
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s, eg. source <(%s --completion=bash)\n", name, name)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprint(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}" cmd="" flag="" i
+    # "=" is a word break, eg. --cmd=/foo is split to --cmd, = and /foo
+    for ((i = 1; i < COMP_CWORD - 1; i++)); do
+        if [[ "${COMP_WORDS[i]}" == "--cmd" && "${COMP_WORDS[i+1]}" == "=" ]]; then
+            cmd="${COMP_WORDS[i+2]}"
+        fi
+    done
+    if [[ "$cur" == "=" ]]; then
+        flag="$prev" cur=""
+    elif [[ "$prev" == "=" ]]; then
+        flag="${COMP_WORDS[COMP_CWORD-2]}"
+    fi
+    if [[ -n "$flag" ]]; then
+        if [[ "$flag" == "--cmd" ]]; then
+`)
+	fmt.Fprintf(w, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(paths, " "))
+	fmt.Fprint(w, `        fi
+        return
+    fi
+`)
+	fmt.Fprintf(w, "    local flags=\"%s\"\n", flagWords(globalCompletions()))
+	fmt.Fprint(w, "    case \"$cmd\" in\n")
+	for _, c := range cmds {
+		if len(c.params) > 0 {
+			fmt.Fprintf(w, "    %s) flags=\"$flags %s\" ;;\n", c.path, flagWords(c.params))
+		}
+	}
+	fmt.Fprint(w, "    esac\n")
+	fmt.Fprint(w, "    COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	fmt.Fprint(w, "}\n")
+	fmt.Fprintf(w, "complete -o nospace -F %s %s\n", fn, name)
+}
+
+// shellQuote quote string in single quotes for shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshArg get spec of param for _arguments, eg. --env=[set running env]:env:
+func zshArg(v *ActionInfoParam) string {
+	usage := zshEscaper.Replace(v.Usage)
+	if v.NameType == "" {
+		return shellQuote("--" + v.Name + "[" + usage + "]")
+	}
+
+	return shellQuote("--" + v.Name + "=[" + usage + "]:" + v.Name + ":")
+}
+
+func zshCompletion(w io.Writer, name string) {
+	fn := completionFuncName(name)
+	cmds := cmdCompletions()
+
+	fmt.Fprintf(w, "#compdef %s\n", name)
+	fmt.Fprintf(w, "# zsh completion for %s, eg. source <(%s --completion=zsh)\n", name, name)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprint(w, "    local context state state_descr line\n    typeset -A opt_args\n")
+	fmt.Fprint(w, "    local -a cmds args\n    cmds=(\n")
+	for _, c := range cmds {
+		fmt.Fprintf(w, "        %s\n", shellQuote(strings.ReplaceAll(c.path, ":", `\:`)+":"+strings.ReplaceAll(c.desc, "\n", " ")))
+	}
+	fmt.Fprint(w, "    )\n    args=(\n")
+	for _, v := range globalCompletions() {
+		if v.Name == "cmd" {
+			fmt.Fprintf(w, "        %s\n", shellQuote("--cmd=["+zshEscaper.Replace(v.Usage)+"]:cmd:->cmds"))
+			continue
+		}
+		fmt.Fprintf(w, "        %s\n", zshArg(v))
+	}
+	fmt.Fprint(w, "    )\n")
+	fmt.Fprint(w, "    case \"${${words[(r)--cmd=*]}#--cmd=}\" in\n")
+	for _, c := range cmds {
+		if len(c.params) == 0 {
+			continue
+		}
+
+		specs := make([]string, 0, len(c.params))
+		for _, v := range c.params {
+			specs = append(specs, zshArg(v))
+		}
+		fmt.Fprintf(w, "    %s) args+=(%s) ;;\n", c.path, strings.Join(specs, " "))
+	}
+	fmt.Fprint(w, "    esac\n")
+	fmt.Fprint(w, "    _arguments $args\n")
+	fmt.Fprint(w, "    if [[ \"$state\" == cmds ]]; then\n        _describe 'cmd' cmds\n    fi\n")
+	fmt.Fprint(w, "}\n")
+	fmt.Fprintf(w, "compdef %s %s\n", fn, name)
+}
+
+// fishArg get complete command of param, condition is added if not empty
+func fishArg(name string, v *ActionInfoParam, condition string) string {
+	s := "complete -c " + name + " -l " + v.Name
+	if v.NameType != "" {
+		s += " -x"
+	}
+
+	if condition != "" {
+		s += " -n " + shellQuote(condition)
+	}
+
+	return s + " -d " + shellQuote(strings.ReplaceAll(v.Usage, "\n", " "))
+}
+
+func fishCompletion(w io.Writer, name string) {
+	fmt.Fprintf(w, "# fish completion for %s, eg. %s --completion=fish | source\n", name, name)
+	fmt.Fprintf(w, "complete -c %s -f\n", name)
+	for _, v := range globalCompletions() {
+		if v.Name == "cmd" {
+			continue
+		}
+		fmt.Fprintln(w, fishArg(name, v, ""))
+	}
+
+	cmds := cmdCompletions()
+	for _, c := range cmds {
+		fmt.Fprintf(w, "complete -c %s -l cmd -x -a %s -d %s\n", name, shellQuote(c.path), shellQuote(strings.ReplaceAll(c.desc, "\n", " ")))
+	}
+
+	for _, c := range cmds {
+		for _, v := range c.params {
+			fmt.Fprintln(w, fishArg(name, v, `string match -q -- "--cmd=`+c.path+`" (commandline -opc)`))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pgo2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newCompletionTestApp() {
+	App(true)
+	App().Container().bind(&syncCommand{}, "command/syncCommand")
+	App().Router().InitHandlers()
+}
+
+func TestCmdCompletions(t *testing.T) {
+	newCompletionTestApp()
+
+	var run *cmdCompletion
+	for _, c := range cmdCompletions() {
+		if c.path == "/sync/run" {
+			run = c
+		}
+	}
+
+	if run == nil {
+		t.Fatal(`cmd /sync/run not found`)
+	}
+
+	names := make([]string, 0, len(run.params))
+	for _, v := range run.params {
+		names = append(names, v.Name)
+	}
+
+	if strings.Join(names, ",") != "id,mode,dry-run,timeout,tags" {
+		t.Fatal(`params mismatch`, names)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	newCompletionTestApp()
+
+	buf := &bytes.Buffer{}
+	bashCompletion(buf, "app.bin")
+	for _, s := range []string{"_pgo2_app_bin()", "/sync/run) flags=\"$flags --id= --mode= --dry-run --timeout= --tags=\"", "--completion=", "complete -o nospace -F _pgo2_app_bin app.bin"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatal(`bash script should contain`, s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	zshCompletion(buf, "app")
+	for _, s := range []string{"#compdef app", "'/sync/run:'", "'--cmd=[set running cmd (optional), eg. --cmd=/foo/bar]:cmd:->cmds'", "'--dry-run[print only]'"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatal(`zsh script should contain`, s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	fishCompletion(buf, "app")
+	for _, s := range []string{"complete -c app -l cmd -x -a '/sync/run'", `complete -c app -l id -x -n 'string match -q -- "--cmd=/sync/run" (commandline -opc)' -d 'user id (required)'`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatal(`fish script should contain`, s, buf.String())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`invalid shell should panic`)
+		}
+	}()
+
+	writeCompletion("powershell")
+}
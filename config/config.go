@@ -24,6 +24,22 @@ func New(basePath, env string) *Config {
 	return config
 }
 
+// NewPath create config from conf path directly, eg. configs/modules/foo,
+// conf path and env path under it are optional.
+func NewPath(confPath, env string) *Config {
+	config := &Config{
+		confPath: confPath,
+		env:      env,
+		parsers:  make(map[string]IConfigParser),
+		data:     make(map[string]interface{}),
+		paths:    make([]string, 0),
+	}
+
+	config.Init()
+
+	return config
+}
+
 // Config the config component
 type Config struct {
 	parsers  map[string]IConfigParser
@@ -31,12 +47,22 @@ type Config struct {
 	paths    []string
 	lock     sync.RWMutex
 	basePath string
+	confPath string
 	env      string
 }
 
+// configPath get path of config files
+func (c *Config) configPath() string {
+	if c.confPath != "" {
+		return c.confPath
+	}
+
+	return filepath.Join(c.basePath, "configs")
+}
+
 // Initialize the
 func (c *Config) Init() {
-	confPath := filepath.Join(c.basePath, "configs")
+	confPath := c.configPath()
 	if f, _ := os.Stat(confPath); f != nil && f.IsDir() {
 		c.paths = append(c.paths, confPath)
 	}
@@ -53,7 +79,7 @@ func (c *Config) Init() {
 }
 
 func (c *Config) CheckPath() error {
-	confPath := filepath.Join(c.basePath, "configs")
+	confPath := c.configPath()
 	if f, _ := os.Stat(confPath); f == nil || !f.IsDir() {
 		return errors.New("Config: invalid configs path, " + confPath)
 	}
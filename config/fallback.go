@@ -0,0 +1,84 @@
+package config
+
+// NewFallback create config which reads from config first,
+// values not found in config are read from fallback,
+// eg. config of module falls back to config of application.
+func NewFallback(config, fallback IConfig) *Fallback {
+	return &Fallback{config: config, fallback: fallback}
+}
+
+// Fallback the config with fallback
+type Fallback struct {
+	config   IConfig
+	fallback IConfig
+}
+
+// pick get config which has the key
+func (f *Fallback) pick(key string, dftSplit ...string) IConfig {
+	if f.config.Get(key, dftSplit...) != nil {
+		return f.config
+	}
+
+	return f.fallback
+}
+
+// AddParser add parser to config
+func (f *Fallback) AddParser(ext string, parser IConfigParser) {
+	f.config.AddParser(ext, parser)
+}
+
+// AddPath add path to config
+func (f *Fallback) AddPath(path string) {
+	f.config.AddPath(path)
+}
+
+func (f *Fallback) GetBool(key string, dft bool, dftSplit ...string) bool {
+	return f.pick(key, dftSplit...).GetBool(key, dft, dftSplit...)
+}
+
+func (f *Fallback) GetInt(key string, dft int, dftSplit ...string) int {
+	return f.pick(key, dftSplit...).GetInt(key, dft, dftSplit...)
+}
+
+func (f *Fallback) GetFloat(key string, dft float64, dftSplit ...string) float64 {
+	return f.pick(key, dftSplit...).GetFloat(key, dft, dftSplit...)
+}
+
+func (f *Fallback) GetString(key string, dft string, dftSplit ...string) string {
+	return f.pick(key, dftSplit...).GetString(key, dft, dftSplit...)
+}
+
+func (f *Fallback) GetSliceBool(key string, dftSplit ...string) []bool {
+	return f.pick(key, dftSplit...).GetSliceBool(key, dftSplit...)
+}
+
+func (f *Fallback) GetSliceInt(key string, dftSplit ...string) []int {
+	return f.pick(key, dftSplit...).GetSliceInt(key, dftSplit...)
+}
+
+func (f *Fallback) GetSliceFloat(key string, dftSplit ...string) []float64 {
+	return f.pick(key, dftSplit...).GetSliceFloat(key, dftSplit...)
+}
+
+func (f *Fallback) GetSliceString(key string, dftSplit ...string) []string {
+	return f.pick(key, dftSplit...).GetSliceString(key, dftSplit...)
+}
+
+// Get get value from config, or from fallback if not found
+func (f *Fallback) Get(key string, dftSplit ...string) interface{} {
+	if v := f.config.Get(key, dftSplit...); v != nil {
+		return v
+	}
+
+	return f.fallback.Get(key, dftSplit...)
+}
+
+// Set set value to config, fallback is not changed
+func (f *Fallback) Set(key string, val interface{}, dftSplit ...string) {
+	f.config.Set(key, val, dftSplit...)
+}
+
+// CheckPath check path of fallback, path of config is optional
+func (f *Fallback) CheckPath() error {
+	return f.fallback.CheckPath()
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	app := New(mockTestBasePath, "")
+	module := NewPath(filepath.Join(mockTestBasePath, "configs/modules/feedback"), "")
+
+	var config IConfig = NewFallback(module, app)
+	if v := config.GetString("testjson.testString", ""); v != "moduleString" {
+		t.Fatal(`config.GetString("testjson.testString", "") !=moduleString`, v)
+	}
+
+	if v := config.GetInt("testjson.testModule", 0); v != 1 {
+		t.Fatal(`config.GetInt("testjson.testModule", 0) !=1`, v)
+	}
+
+	if v := config.GetInt("testjson.testInt", 0); v != 123 {
+		t.Fatal(`config.GetInt("testjson.testInt", 0) !=123`, v)
+	}
+
+	if v := config.GetSliceString("testjson.testSString"); len(v) != 2 {
+		t.Fatal(`len(config.GetSliceString("testjson.testSString")) !=2`)
+	}
+
+	config.Set("testjson.testInt", 456)
+	if v := config.GetInt("testjson.testInt", 0); v != 456 {
+		t.Fatal(`config.GetInt("testjson.testInt", 0) !=456`, v)
+	}
+
+	if v := app.GetInt("testjson.testInt", 0); v != 123 {
+		t.Fatal(`app.GetInt("testjson.testInt", 0) !=123`, v)
+	}
+}
+
+func TestNewPath(t *testing.T) {
+	config := NewPath(filepath.Join(mockTestBasePath, "notExists"), "")
+	if v := config.Get("testjson.testInt"); v != nil {
+		t.FailNow()
+	}
+}
@@ -14,6 +14,8 @@ type bindItem struct {
 	zero  reflect.Value // zero value
 	cmIdx int           // construct index
 	pmIdx int           // prepare index
+
+	injects []*injectField // fields tagged with inject
 }
 
 const (
@@ -24,6 +26,7 @@ const (
 // Container the container component, configuration:
 // container:
 //     enablePool: on/off
+//     appPkg: "github.com/foo/bar" // package prefix of @app, default is main module
 func NewContainer(enable string) *Container {
 	if enable == "" {
 		enable = EnablePoolOn
@@ -38,11 +41,24 @@ func NewContainer(enable string) *Container {
 type Container struct {
 	enablePool string
 	items      map[string]*bindItem
+	appPkg     string // package prefix of application for @app of inject
+}
+
+// SetAppPkg set package prefix of application, @app of inject is replaced with it
+func (c *Container) SetAppPkg(pkg string) {
+	c.appPkg = strings.TrimSuffix(pkg, "/")
 }
 
-// Bind bind template object to class,
-// param i must be a pointer of struct.
+// Bind bind template object to class, param i must be a pointer
+// of struct, fields tagged with inject are set by Get, eg.
+// Redis *adapter.Redis `inject:"redis"`
 func (c *Container) Bind(i interface{}) string {
+	return c.bind(i, "")
+}
+
+// bind bind template object to class, class name
+// is got from package path of object if name is empty.
+func (c *Container) bind(i interface{}, name string) string {
 	iv := reflect.ValueOf(i)
 	if iv.Kind() != reflect.Ptr {
 		panic("Container: invalid type, need pointer")
@@ -53,6 +69,11 @@ func (c *Container) Bind(i interface{}) string {
 	item := bindItem{zero: reflect.Zero(rt), cmIdx: -1, pmIdx: -1}
 	item.pool.New = func() interface{} { return reflect.New(rt) }
 
+	// get fields to inject
+	if rt.Kind() == reflect.Struct {
+		item.injects = injectFields(rt)
+	}
+
 	// get binding info
 	if bind, ok := i.(iface.IBind); ok {
 		item.info = bind.GetBindInfo(i)
@@ -71,20 +92,27 @@ func (c *Container) Bind(i interface{}) string {
 	}
 
 	// get class name
-	pkgPath := rt.PkgPath()
-
-	if index := strings.Index(pkgPath, "/"+ControllerWebPkg); index >= 0 {
-		pkgPath = pkgPath[index+1:]
-	}
+	if name == "" {
+		pkgPath := rt.PkgPath()
+
+		if index := modulePkgIndex(pkgPath); index >= 0 {
+			// controller of module, eg. modules/feedback/controller/api
+			pkgPath = pkgPath[index+1:]
+		} else {
+			if index := strings.Index(pkgPath, "/"+ControllerWebPkg); index >= 0 {
+				pkgPath = pkgPath[index+1:]
+			}
 
-	if index := strings.Index(pkgPath, "/"+ControllerCmdPkg); index >= 0 {
-		pkgPath = pkgPath[index+1:]
-	}
+			if index := strings.Index(pkgPath, "/"+ControllerCmdPkg); index >= 0 {
+				pkgPath = pkgPath[index+1:]
+			}
+		}
 
-	name := pkgPath + "/" + rt.Name()
+		name = pkgPath + "/" + rt.Name()
 
-	if len(name) > VendorLength && name[:VendorLength] == VendorPrefix {
-		name = name[VendorLength:]
+		if len(name) > VendorLength && name[:VendorLength] == VendorPrefix {
+			name = name[VendorLength:]
+		}
 	}
 
 	c.items[name] = &item
@@ -92,6 +120,22 @@ func (c *Container) Bind(i interface{}) string {
 	return name
 }
 
+// modulePkgIndex get index of module in controller package path,
+// eg. github.com/foo/bar/modules/feedback/controller/api, -1 if not found
+func modulePkgIndex(pkgPath string) int {
+	index := strings.LastIndex(pkgPath, "/"+ModulePkg+"/")
+	if index < 0 {
+		return -1
+	}
+
+	parts := strings.SplitN(pkgPath[index+1:], "/", 4)
+	if len(parts) < 3 || (parts[2] != ControllerWebPkg && parts[2] != ControllerCmdPkg) {
+		return -1
+	}
+
+	return index
+}
+
 // Has check if the class exists in container
 func (c *Container) Has(name string) bool {
 	_, ok := c.items[name]
@@ -148,6 +192,11 @@ func (c *Container) Get(name string, ctx iface.IContext, params ...interface{})
 		obj.SetContext(ctx)
 	}
 
+	// inject fields before Prepare()
+	if len(item.injects) > 0 {
+		c.inject(item, rv, ctx)
+	}
+
 	// call Prepare()
 	if item.pmIdx != -1 {
 		if im := rv.Method(item.pmIdx); im.IsValid() {
@@ -3,7 +3,10 @@ package pgo2
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/pinguo/pgo2/logs"
 )
 
 type containerTestCommand struct {
@@ -74,3 +77,140 @@ func TestContainer(t *testing.T) {
 	})
 
 }
+
+func TestModulePkgIndex(t *testing.T) {
+	if i := modulePkgIndex("github.com/foo/bar/modules/feedback/controller/api"); i != 18 {
+		t.Fatal(`modulePkgIndex != 18`, i)
+	}
+
+	if i := modulePkgIndex("github.com/foo/bar/modules/feedback/service"); i != -1 {
+		t.Fatal(`modulePkgIndex != -1`, i)
+	}
+
+	if i := modulePkgIndex("github.com/foo/bar/controller/modules"); i != -1 {
+		t.Fatal(`modulePkgIndex != -1`, i)
+	}
+}
+
+type injectTestService struct {
+	Object
+	Log    *logs.Log `inject:"log"`
+	params []interface{}
+}
+
+func (s *injectTestService) Prepare(params ...interface{}) {
+	s.params = params
+}
+
+type injectTestRedis struct {
+	Object
+	id interface{}
+}
+
+func (r *injectTestRedis) Prepare(componentId ...interface{}) {
+	if len(componentId) > 0 {
+		r.id = componentId[0]
+	}
+}
+
+type injectTestModel struct {
+	Object
+	Service *injectTestService `inject:"@app/service/injectTestService"`
+	Redis   *injectTestRedis   `inject:"testRedis:redisCache"`
+	log     *logs.Log
+}
+
+func (m *injectTestModel) Prepare() {
+	// injected fields are available in Prepare
+	m.log = m.Service.Log
+}
+
+type injectTestInvalid struct {
+	Object
+	Service *injectTestModel `inject:"@app/service/injectTestService"`
+}
+
+type injectTestA struct {
+	Object
+	B *injectTestB `inject:"@app/service/injectTestB"`
+}
+
+type injectTestB struct {
+	Object
+	A *injectTestA `inject:"@app/service/injectTestA"`
+}
+
+func TestContainer_ValidateInjects(t *testing.T) {
+	App(true)
+	container := NewContainer("on")
+	container.bind(&injectTestService{}, "app/service/injectTestService")
+	container.bind(&injectTestRedis{}, adapterClassPrefix+"TestRedis")
+	container.Bind(&injectTestModel{})
+	container.ValidateInjects()
+
+	container.bind(&injectTestA{}, "app/service/injectTestA")
+	container.bind(&injectTestB{}, "app/service/injectTestB")
+	defer func() {
+		if err, _ := recover().(string); !strings.Contains(err, "inject cycle") {
+			t.Fatal(`inject cycle should panic`, err)
+		}
+	}()
+
+	container.ValidateInjects()
+}
+
+func TestContainer_Inject(t *testing.T) {
+	App(true)
+	container := NewContainer("on")
+	container.bind(&injectTestService{}, "app/service/injectTestService")
+	container.bind(&injectTestRedis{}, adapterClassPrefix+"TestRedis")
+	className := container.Bind(&injectTestModel{})
+
+	m := container.Get(className, &Context{}).Interface().(*injectTestModel)
+	if m.Service == nil || m.Service.Log != App().Log() || m.log != App().Log() {
+		t.Fatal(`service and log should be injected`, m.Service)
+	}
+
+	if len(m.Service.params) != 0 || m.Redis == nil || m.Redis.id != "redisCache" {
+		t.Fatal(`redis should be injected with component id`, m.Redis)
+	}
+
+	if m.Service.Context() != m.Context() {
+		t.Fatal(`injected object should share context`)
+	}
+
+	invalid := container.Bind(&injectTestInvalid{})
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal(`unassignable inject should panic`)
+				}
+			}()
+
+			container.Get(invalid, &Context{})
+		}()
+	}
+
+	// @app is matched by path suffix if package prefix is unknown
+	container.bind(&injectTestService{}, "github.com/foo/bar/service/injectTestService")
+	if _, err := container.injectClass("@app/service/injectTestService"); !strings.Contains(err, "ambiguous") {
+		t.Fatal(`ambiguous inject class should fail`, err)
+	}
+
+	container.SetAppPkg("github.com/foo/bar")
+	if class, _ := container.injectClass("@app/service/injectTestService"); class != "github.com/foo/bar/service/injectTestService" {
+		t.Fatal(`inject class should be resolved with package prefix`, class)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`unexported inject field should panic`)
+		}
+	}()
+
+	container.Bind(&struct {
+		Object
+		log *logs.Log `inject:"log"`
+	}{})
+}
@@ -1,6 +1,7 @@
 package pgo2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -43,6 +44,11 @@ type Context struct {
 	accessLogFormat iface.IAccessLogFormat
 
 	queryCache url.Values
+	pathParams map[string]string
+
+	goCtx   context.Context
+	failure interface{} // panic or error of last process, kept after finish
+	result  map[string]interface{} // json output of command, kept after finish
 
 	logs.Profiler
 	logs.Logger
@@ -75,6 +81,8 @@ func (c *Context) reset() {
 	c.actionId = ""
 	c.userData = nil
 	c.queryCache = nil
+	c.pathParams = nil
+	c.goCtx = nil
 	c.Profiler.Reset()
 }
 
@@ -99,6 +107,8 @@ func (c *Context) Start(plugins []iface.IPlugin) {
 	// restart
 	c.startTime = time.Now()
 	c.index = -1
+	c.failure = nil
+	c.result = nil
 	if c.plugins != nil {
 		c.Logger.SetLogId(logId)
 	} else {
@@ -111,6 +121,7 @@ func (c *Context) Start(plugins []iface.IPlugin) {
 func (c *Context) finish(goLog bool) {
 	// process unhandled panic
 	if v := recover(); v != nil {
+		c.failure = v
 		status := http.StatusInternalServerError
 		switch e := v.(type) {
 		case *perror.Error:
@@ -382,6 +393,49 @@ func (c *Context) PostAll() map[string]string {
 	return m
 }
 
+// SetPathParams set named params captured by route rule
+func (c *Context) SetPathParams(params map[string]string) {
+	c.pathParams = params
+}
+
+// PathParam get named param captured by route rule, eg. id of /user/{id:int}
+func (c *Context) PathParam(name, dft string) string {
+	if v, ok := c.pathParams[name]; ok && len(v) > 0 {
+		return v
+	}
+
+	return dft
+}
+
+// PathParamAll get all named params captured by route rule
+func (c *Context) PathParamAll() map[string]string {
+	m := make(map[string]string, len(c.pathParams))
+	for k, v := range c.pathParams {
+		m[k] = v
+	}
+
+	return m
+}
+
+// SetGoContext set the go context which carries cancellation of current context
+func (c *Context) SetGoContext(ctx context.Context) {
+	c.goCtx = ctx
+}
+
+// GoContext get the go context of current context, it is the request's
+// context for web, and it is cancelled on SIGINT/SIGTERM for daemon command
+func (c *Context) GoContext() context.Context {
+	if c.goCtx != nil {
+		return c.goCtx
+	}
+
+	if c.input != nil {
+		return c.input.Context()
+	}
+
+	return context.Background()
+}
+
 // Param get first param value by name, post take precedence over get
 func (c *Context) Param(name, dft string) string {
 	if c.input != nil {
@@ -654,7 +708,7 @@ func (c *Context) End(status int, data []byte) {
 		c.SetHeader("X-Cost-Time", fmt.Sprintf("%dms", c.ElapseMs()))
 		c.output.WriteHeader(status)
 		c.output.Write(data)
-	} else if len(data) > 0 {
+	} else if len(data) > 0 && !outputJson() {
 		os.Stdout.Write(data)
 		os.Stdout.WriteString("\n")
 	}
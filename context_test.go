@@ -2,6 +2,7 @@ package pgo2
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -525,3 +526,45 @@ func TestContext_ParamArray(t *testing.T) {
 	}
 
 }
+
+func TestContext_PathParam(t *testing.T) {
+	context := &Context{}
+	if context.PathParam("id", "dft") != "dft" || len(context.PathParamAll()) != 0 {
+		t.FailNow()
+	}
+
+	context.SetPathParams(map[string]string{"id": "12"})
+	if context.PathParam("id", "") != "12" || context.PathParamAll()["id"] != "12" {
+		t.FailNow()
+	}
+
+	context.reset()
+	if context.PathParam("id", "") != "" {
+		t.FailNow()
+	}
+}
+
+func TestContext_GoContext(t *testing.T) {
+	ctx := &Context{}
+	if ctx.GoContext() != context.Background() {
+		t.Fatal("GoContext() should be background without input")
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	ctx.SetInput(r)
+	if ctx.GoContext() != r.Context() {
+		t.Fatal("GoContext() should be context of input")
+	}
+
+	goCtx, cancel := context.WithCancel(context.Background())
+	ctx.SetGoContext(goCtx)
+	cancel()
+	if ctx.GoContext().Err() != context.Canceled {
+		t.Fatal("GoContext() should be cancelled")
+	}
+
+	ctx.reset()
+	if ctx.goCtx != nil {
+		t.Fatal("goCtx should be nil after reset")
+	}
+}
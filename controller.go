@@ -1,8 +1,13 @@
 package pgo2
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/pinguo/pgo2/iface"
@@ -71,18 +76,17 @@ func (c *Controller) HandlePanic(v interface{}, debug bool) {
 		}
 	}
 
-	switch e := v.(type) {
-	case *perror.Error:
+	if e, ok := asPError(v); ok {
 		status = e.Status()
 		pErrorType = e.ErrType()
 
 		defer recoverErr(e.Message())
 
-		App().Router().ErrorController(c.Context()).(iface.IErrorController).Error(status, e.Message())
-	default:
+		App().Router().ErrorResponse(c.Context(), status, e.Message(), e)
+	} else {
 		defer recoverErr("")
 
-		App().Router().ErrorController(c.Context(), status).(iface.IErrorController).Error(status, "")
+		App().Router().ErrorResponse(c.Context(), status, "", nil, status)
 	}
 
 	if status == http.StatusOK {
@@ -91,31 +95,57 @@ func (c *Controller) HandlePanic(v interface{}, debug bool) {
 
 	switch pErrorType {
 	case perror.ErrTypeWarn:
-		c.Context().Warn("%s, trace[%s]", util.ToString(v), util.PanicTrace(TraceMaxDepth, false, debug))
+		c.Context().Warn("%s, trace[%s]", util.ToString(v), errorTrace(v, debug))
 	case perror.ErrTypeIgnore:
 	default:
-		c.Context().Error("%s, trace[%s]", util.ToString(v), util.PanicTrace(TraceMaxDepth, false, debug))
+		c.Context().Error("%s, trace[%s]", util.ToString(v), errorTrace(v, debug))
 	}
 
 }
 
+// asPError get perror.Error of panic value, wrapped error is unwrapped
+// as Response does, eg. fmt.Errorf("...: %w", perr)
+func asPError(v interface{}) (*perror.Error, bool) {
+	var pErr *perror.Error
+	if err, ok := v.(error); ok && errors.As(err, &pErr) {
+		return pErr, true
+	}
+
+	return nil, false
+}
+
+// errorTrace get trace of panic value, the stack captured by
+// perror.Error is used if exists
+func errorTrace(v interface{}, debug bool) string {
+	if e, ok := asPError(v); ok && e.HasStack() && !debug {
+		return e.StackTrace(TraceMaxDepth, false)
+	}
+
+	return util.PanicTrace(TraceMaxDepth, false, debug)
+}
+
 // Response response values action returned
 func (c *Controller) Response(v interface{}, err error) {
 	if err != nil {
-		errCtl := App().Router().ErrorController(c.Context()).(iface.IErrorController)
-		if pErr, ok := err.(*perror.Error); ok {
+		router := App().Router()
+		var pErr *perror.Error
+		if errors.As(err, &pErr) {
 			switch pErr.ErrType() {
 			case perror.ErrTypeError:
-				c.Context().Error(pErr.Error())
+				if pErr.HasStack() {
+					c.Context().Error("%s, trace[%s]", err.Error(), pErr.StackTrace(TraceMaxDepth, false))
+				} else {
+					c.Context().Error(err.Error())
+				}
 			case perror.ErrTypeWarn:
-				c.Context().Warn(pErr.Error())
+				c.Context().Warn(err.Error())
 			}
 
-			errCtl.Error(pErr.Status(), pErr.Message())
+			router.ErrorResponse(c.Context(), pErr.Status(), pErr.Message(), pErr)
 			return
 		}
 
-		errCtl.Error(http.StatusInternalServerError, err.Error())
+		router.ErrorResponse(c.Context(), http.StatusInternalServerError, err.Error(), err)
 		return
 	}
 
@@ -138,6 +168,32 @@ func (c *Controller) Redirect(location string, permanent bool) {
 	}
 }
 
+// NotModified set ETag and Last-Modified headers by the version and
+// modification time of resource, empty etag or zero modTime is omitted.
+// 304 is responded if the copy of client is fresh, in which case
+// true is returned and action should return immediately.
+func (c *Controller) NotModified(etag string, modTime time.Time) bool {
+	ctx := c.Context()
+	if etag != "" {
+		if etag[0] != '"' && !strings.HasPrefix(etag, "W/") {
+			etag = `W/"` + etag + `"`
+		}
+		ctx.SetHeader("Etag", etag)
+	}
+
+	if !modTime.IsZero() {
+		ctx.SetHeader("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if ctx.Input() == nil || !render.NotModified(ctx.Input(), etag, modTime) {
+		return false
+	}
+
+	ctx.PushLog("status", http.StatusNotModified)
+	ctx.End(http.StatusNotModified, nil)
+	return true
+}
+
 // Json output json response
 func (c *Controller) Json(data interface{}, status int, msg ...string) {
 	out := map[string]interface{}{
@@ -161,6 +217,11 @@ func (c *Controller) json(out map[string]interface{}, status int, msg ...string)
 	message := App().Status().Text(status, ctx.Header("Accept-Language", ""), msg...)
 	out["status"] = status
 	out["message"] = message
+	// keep output of command for json output mode
+	if cc, ok := ctx.(*Context); ok && ctx.Output() == nil {
+		cc.result = out
+	}
+
 	r := render.NewJson(out)
 	ctx.PushLog("status", status)
 	ctx.SetHeader("Content-Type", r.ContentType())
@@ -254,13 +315,18 @@ func (c *Controller) ProtoBuf(data interface{}, statuses ...int) {
 	ctx.End(httpStatus, r.Content())
 }
 
-// Render Custom renderer
+// Render Custom renderer, render.StreamRender is written without buffering
 func (c *Controller) Render(r render.Render, statuses ...int) {
 	status := http.StatusOK
 	if len(statuses) > 0 {
 		status = statuses[0]
 	}
 
+	if sr, ok := r.(render.StreamRender); ok {
+		c.stream(sr)
+		return
+	}
+
 	ctx := c.Context()
 	ctx.PushLog("status", status)
 	ctx.SetHeader("Content-Type", r.ContentType())
@@ -272,6 +338,59 @@ func (c *Controller) Render(r render.Render, statuses ...int) {
 	ctx.End(httpStatus, r.Content())
 }
 
+// Download output content of reader as attachment without buffering,
+// Range requests are supported if reader is an io.ReadSeeker
+func (c *Controller) Download(reader io.Reader, name string) {
+	r := render.NewStream(reader, name)
+	r.SetAttachment(name)
+	c.stream(r)
+}
+
+// Rows output rows incrementally, the format is negotiated by Accept
+// header between NDJSON and CSV, NDJSON is used by default.
+func (c *Controller) Rows(rows render.RowsFunc) {
+	accept := c.Context().Header("Accept", "")
+	switch render.Negotiate(accept, render.ContentTypeNdJson, render.ContentTypeCsv) {
+	case render.ContentTypeCsv:
+		c.stream(render.NewCsv(rows))
+	default:
+		c.stream(render.NewNdJson(rows))
+	}
+}
+
+// stream output stream response
+func (c *Controller) stream(r render.StreamRender) {
+	ctx := c.Context()
+	httpStatus := r.HttpCode()
+	if ctx.Status() > 0 && ctx.Status() != httpStatus {
+		httpStatus = ctx.Status()
+	}
+
+	r.SetHttpCode(httpStatus)
+
+	if ctx.Output() == nil {
+		ctx.PushLog("status", httpStatus)
+		// reader of rows render starts writing rows, get it only once
+		reader := r.Reader()
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		if _, e := io.Copy(os.Stdout, reader); e != nil {
+			ctx.Warn("failed to write stream, %s", e.Error())
+		}
+		return
+	}
+
+	ctx.SetHeader("X-Log-Id", ctx.LogId())
+	ctx.SetHeader("X-Cost-Time", fmt.Sprintf("%dms", ctx.ElapseMs()))
+	e := r.Serve(ctx.Output(), ctx.Input())
+	ctx.PushLog("status", ctx.Status())
+	if e != nil {
+		ctx.Warn("failed to write stream, %s", e.Error())
+	}
+}
+
 // View output rendered view
 func (c *Controller) View(view string, data interface{}, contentTypes ...string) {
 	ctx := c.Context()
@@ -294,6 +413,57 @@ func (c *Controller) Error(status int, message string) {
 	c.Json(EmptyObject, status, message)
 }
 
+// Problem output RFC 7807 problem details, status and title are of the
+// http status, app code is set to the code member if status is not a
+// http status, detail is the translated message and instance is the
+// log id, type and extensions are taken from err if it's a *perror.Error.
+func (c *Controller) Problem(status int, message string, err error) {
+	ctx := c.Context()
+	lang := ctx.Header("Accept-Language", "")
+	// status member must be the http status, app code is kept in code member
+	httpStatus := status
+	var pErr *perror.Error
+	if errors.As(err, &pErr) {
+		httpStatus = pErr.HttpStatus()
+	} else if http.StatusText(status) == "" {
+		httpStatus = http.StatusInternalServerError
+	}
+
+	if message != "" && lang != "" {
+		message = App().I18n().Translate(message, lang)
+	}
+
+	r := render.NewProblem(httpStatus, App().Status().Text(httpStatus, lang, http.StatusText(httpStatus)), message)
+	r.Instance = ctx.LogId()
+	if pErr != nil {
+		r.Type = App().Router().ProblemType(pErr.Type())
+		r.Extensions = make(map[string]interface{}, len(pErr.Extensions())+1)
+		for k, v := range pErr.Extensions() {
+			r.Extensions[k] = v
+		}
+	}
+
+	if status != httpStatus {
+		if r.Extensions == nil {
+			r.Extensions = make(map[string]interface{}, 1)
+		}
+		r.Extensions["code"] = status
+	}
+
+	ctx.PushLog("status", status)
+	ctx.SetHeader("Content-Type", r.ContentType())
+	ctx.End(r.HttpCode(), r.Content())
+}
+
+// Module get module of controller, nil if controller is not in any module
+func (c *Controller) Module() *Module {
+	if name := App().Router().Module(c.Context().ControllerId()); name != "" {
+		return App().Module(name)
+	}
+
+	return nil
+}
+
 // SetActionDesc
 // Deprecated: Delete the next version directly
 func (c *Controller) SetActionDesc(message string) {
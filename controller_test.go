@@ -2,14 +2,20 @@ package pgo2
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/pinguo/pgo2/logs"
+	"github.com/pinguo/pgo2/perror"
 	"github.com/pinguo/pgo2/render"
 )
 
@@ -53,6 +59,17 @@ func TestController_HandlePanic(t *testing.T) {
 
 }
 
+func TestAsPError(t *testing.T) {
+	pErr := perror.NewWarn(http.StatusNotFound, "user not found")
+	if e, ok := asPError(fmt.Errorf("load user: %w", pErr)); !ok || e != pErr {
+		t.Fatal("wrapped perror should be unwrapped")
+	}
+
+	if _, ok := asPError("testerr"); ok {
+		t.Fatal("string should not be perror")
+	}
+}
+
 func TestController_Json(t *testing.T) {
 
 	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
@@ -291,3 +308,160 @@ func TestController_View(t *testing.T) {
 	}
 
 }
+
+func TestController_Download(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+
+	t.Run("range", func(t *testing.T) {
+		context := &Context{}
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.Header.Set("Range", "bytes=2-4")
+		w := httptest.NewRecorder()
+		context.HttpRW(false, true, r, w)
+
+		mockC := &Controller{}
+		mockC.SetContext(context)
+		mockC.Download(strings.NewReader("0123456789"), "data.csv")
+
+		if w.Code != http.StatusPartialContent {
+			t.Fatal("w.Code != http.StatusPartialContent")
+		}
+
+		if w.Body.String() != "234" {
+			t.Fatal(`w.Body.String() != "234"`)
+		}
+
+		if w.Result().Header.Get("Content-Disposition") != `attachment; filename=data.csv` {
+			t.Fatal(`Content-Disposition != attachment; filename=data.csv`)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		context := &Context{}
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.Header.Set("Range", "bytes=2-4")
+		w := httptest.NewRecorder()
+		context.HttpRW(false, true, r, w)
+
+		mockC := &Controller{}
+		mockC.SetContext(context)
+		mockC.Render(render.NewStream(bytes.NewBufferString("0123456789"), "data.txt"))
+
+		if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+			t.Fatal(`w.Body.String() != "0123456789"`)
+		}
+
+		if w.Result().Header.Get("Accept-Ranges") != "none" {
+			t.Fatal(`w.Result().Header.Get("Accept-Ranges") != "none"`)
+		}
+	})
+}
+
+func TestController_Rows(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+	context := &Context{}
+	r := httptest.NewRequest("GET", "/test", nil)
+	r.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	context.HttpRW(false, true, r, w)
+
+	mockC := &Controller{}
+	mockC.SetContext(context)
+	mockC.Rows(render.SliceRows([][]string{{"a", "b"}}))
+
+	if w.Body.String() != "a,b\n" {
+		t.Fatal(`w.Body.String() != "a,b\n"`)
+	}
+
+	if w.Result().Header.Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatal(`w.Result().Header.Get("Content-Type") != "text/csv; charset=utf-8"`)
+	}
+}
+
+func TestController_RowsCmd(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+	context := &Context{}
+	mockC := &Controller{}
+	mockC.SetContext(context)
+
+	var calls int32
+	mockC.Render(render.NewNdJson(func(yield func(row interface{}) error) error {
+		atomic.AddInt32(&calls, 1)
+		return yield(map[string]int{"a": 1})
+	}))
+
+	// wait for writers started by extra Reader() calls
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatal(`rows should be iterated once, got`, n)
+	}
+}
+
+func TestController_NotModified(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+	context := &Context{}
+	r := httptest.NewRequest("GET", "/test", nil)
+	r.Header.Set("If-None-Match", `W/"v1"`)
+	w := httptest.NewRecorder()
+	context.HttpRW(false, true, r, w)
+
+	mockC := &Controller{}
+	mockC.SetContext(context)
+
+	if mockC.NotModified("v2", time.Time{}) {
+		t.Fatal(`mockC.NotModified("v2") == true`)
+	}
+
+	if mockC.NotModified("v1", time.Now()) == false {
+		t.Fatal(`mockC.NotModified("v1") == false`)
+	}
+
+	if w.Code != http.StatusNotModified || w.Header().Get("Etag") != `W/"v1"` {
+		t.Fatal(`w.Code != http.StatusNotModified`)
+	}
+}
+
+func TestController_Problem(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+	context := &Context{}
+	r := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	context.HttpRW(false, true, r, w)
+	context.SetLogId("abc")
+
+	mockC := &Controller{}
+	mockC.SetContext(context)
+
+	err := perror.NewWarn(http.StatusBadRequest, "age is too small").WithType("about:blank#age").WithExtension("min", 10)
+	mockC.Problem(err.Status(), err.Message(), err)
+
+	out := make(map[string]interface{})
+	if e := json.Unmarshal(w.Body.Bytes(), &out); e != nil {
+		t.Fatal(e)
+	}
+
+	if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != render.ContentTypeProblem {
+		t.Fatal("w.Code != http.StatusBadRequest")
+	}
+
+	if out["type"] != "about:blank#age" || out["title"] != "Bad Request" || out["detail"] != "age is too small" ||
+		out["min"] != float64(10) || out["instance"] != "abc" {
+		t.Fatalf("unexpected problem: %v", out)
+	}
+
+	perror.RegisterCode(11002, http.StatusForbidden, "quota exceeded")
+	w = httptest.NewRecorder()
+	context.HttpRW(false, true, r, w)
+	err = perror.NewWarn(11002)
+	mockC.Problem(err.Status(), err.Message(), err)
+
+	out = make(map[string]interface{})
+	if e := json.Unmarshal(w.Body.Bytes(), &out); e != nil {
+		t.Fatal(e)
+	}
+
+	if w.Code != http.StatusForbidden || out["status"] != float64(http.StatusForbidden) || out["title"] != "Forbidden" ||
+		out["code"] != float64(11002) || err.Extensions() != nil {
+		t.Fatalf("unexpected problem of app code: %d %v", w.Code, out)
+	}
+}
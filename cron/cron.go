@@ -0,0 +1,215 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule parsed cron expression, standard 5 fields are supported:
+// minute hour day-of-month month day-of-week, eg. "*/5 1-6 * * MON-FRI",
+// and descriptors: @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight, @hourly, @every <duration>.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	every                         time.Duration
+}
+
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	descriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Parse parse cron expression
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, err
+		}
+
+		if every < time.Second {
+			return nil, errors.New("cron: @every duration must be at least 1s")
+		}
+
+		return &Schedule{every: every}, nil
+	}
+
+	if v, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = v
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: expected 5 fields, got %d, spec:%s", len(fields), spec)
+	}
+
+	s := &Schedule{domStar: fields[2] == "*" || fields[2] == "?", dowStar: fields[4] == "*" || fields[4] == "?"}
+	bits := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, f := range []field{minuteField, hourField, domField, monthField, dowField} {
+		v, err := f.parse(fields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		*bits[i] = v
+	}
+
+	// 7 is sunday too
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// MustParse parse cron expression, panic if failed
+func MustParse(spec string) *Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return s
+}
+
+// parse parse field of list, eg. 1,3-5,*/10
+func (f field) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+		if pos := strings.Index(item, "/"); pos >= 0 {
+			n, err := strconv.Atoi(item[pos+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: invalid step, %s", item)
+			}
+
+			step, item = n, item[:pos]
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case item == "*" || item == "?":
+		case strings.Contains(item, "-"):
+			pos := strings.Index(item, "-")
+			var err error
+			if low, err = f.value(item[:pos]); err != nil {
+				return 0, err
+			}
+
+			if high, err = f.value(item[pos+1:]); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(item)
+			if err != nil {
+				return 0, err
+			}
+
+			low, high = v, v
+			if step > 1 {
+				high = f.max
+			}
+		}
+
+		if low > high {
+			return 0, fmt.Errorf("cron: invalid range, %s", item)
+		}
+
+		for i := low; i <= high; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// value parse number or name of field
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: invalid value %s, need %d-%d", s, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// Next get next activation time after t in location of t,
+// zero time is returned if not found in five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every - time.Duration(t.Nanosecond())*time.Nanosecond)
+	}
+
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatch day of month or day of week match, both must match if either is "*"
+func (s *Schedule) dayMatch(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
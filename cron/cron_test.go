@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "*/5 1-6 * * MON-FRI", "0 0 1,15 jan,jul ?", "@daily", "@every 90s"} {
+		if _, err := Parse(spec); err != nil {
+			t.Fatal(spec, err)
+		}
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "* 5-1 * * *", "*/0 * * * *", "* * * foo *", "@every 1ms"} {
+		if _, err := Parse(spec); err == nil {
+			t.Fatal(spec + " should be invalid")
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+	now := time.Date(2020, 1, 31, 23, 58, 30, 0, loc) // friday
+
+	tests := map[string]time.Time{
+		"* * * * *":       time.Date(2020, 1, 31, 23, 59, 0, 0, loc),
+		"*/15 * * * *":    time.Date(2020, 2, 1, 0, 0, 0, 0, loc),
+		"30 9 * * MON":    time.Date(2020, 2, 3, 9, 30, 0, 0, loc),
+		"0 0 29 2 *":      time.Date(2020, 2, 29, 0, 0, 0, 0, loc),
+		"0 12 1 * 7":      time.Date(2020, 2, 1, 12, 0, 0, 0, loc),
+		"0 12 10 * SUN":   time.Date(2020, 2, 2, 12, 0, 0, 0, loc),
+		"@yearly":         time.Date(2021, 1, 1, 0, 0, 0, 0, loc),
+		"@every 1m30s":    time.Date(2020, 2, 1, 0, 0, 0, 0, loc),
+		"5-10/5 22 * * *": time.Date(2020, 2, 1, 22, 5, 0, 0, loc),
+	}
+
+	for spec, want := range tests {
+		if next := MustParse(spec).Next(now); !next.Equal(want) {
+			t.Fatal(spec, next, want)
+		}
+	}
+
+	shanghai := time.FixedZone("CST", 8*3600)
+	if next := MustParse("0 8 * * *").Next(now.In(shanghai)); !next.Equal(time.Date(2020, 2, 1, 0, 0, 0, 0, loc)) {
+		t.Fatal("next should be in location of t", next.UTC())
+	}
+}
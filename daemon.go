@@ -0,0 +1,122 @@
+package pgo2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultDaemonBackoff    = time.Second
+	DefaultDaemonMaxBackoff = time.Minute
+)
+
+// SetDaemonBackoff set initial delay to restart a panicked daemon worker
+func (s *Server) SetDaemonBackoff(v string) {
+	if backoff, err := time.ParseDuration(v); err != nil {
+		panic(fmt.Sprintf("Server: SetDaemonBackoff failed, val:%s, err:%s", v, err.Error()))
+	} else {
+		s.daemonBackoff = backoff
+	}
+}
+
+// SetDaemonMaxBackoff set max delay to restart a panicked daemon worker
+func (s *Server) SetDaemonMaxBackoff(v string) {
+	if backoff, err := time.ParseDuration(v); err != nil {
+		panic(fmt.Sprintf("Server: SetDaemonMaxBackoff failed, val:%s, err:%s", v, err.Error()))
+	} else {
+		s.daemonMaxBackoff = backoff
+	}
+}
+
+// ServeDaemon run command with n workers until SIGINT/SIGTERM, each
+// worker runs the command with a copied context whose GoContext() is
+// cancelled on signal, worker id is stored in user data DaemonWorkerKey.
+// a worker is restarted with exponential backoff if its action panics or
+// returns error, ServeDaemon returns after all workers exit.
+func (s *Server) ServeDaemon(n int) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	go func() {
+		select {
+		case v := <-sig:
+			GLogger().Info("daemon receive signal " + v.String() + ", stop workers")
+			cancel()
+		case <-goCtx.Done():
+		}
+	}()
+
+	s.runDaemon(goCtx, n)
+}
+
+// runDaemon run n workers until goCtx is done
+func (s *Server) runDaemon(goCtx context.Context, n int) {
+	// initialize log before workers
+	App().Log()
+	GLogger()
+
+	base := &Context{debug: s.debug}
+	base.SetEnableAccessLog(s.enableAccessLog)
+	base.SetAccessLogFormat(s.accessLogFormat)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			s.runWorker(goCtx, base, id)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// runWorker run command repeatedly while it fails and goCtx is not done
+func (s *Server) runWorker(goCtx context.Context, base *Context, id int) {
+	backoff := s.daemonBackoff
+	for {
+		start := time.Now()
+		failure := s.runOnce(goCtx, base, id)
+		if failure == nil || goCtx.Err() != nil {
+			return
+		}
+
+		// reset backoff if worker has run for a long time
+		if time.Since(start) > s.daemonMaxBackoff {
+			backoff = s.daemonBackoff
+		}
+
+		GLogger().Error(fmt.Sprintf("daemon worker %d failed, restart after %s, err:%v", id, backoff, failure))
+
+		select {
+		case <-goCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > s.daemonMaxBackoff {
+			backoff = s.daemonMaxBackoff
+		}
+	}
+}
+
+// runOnce run command once with a copied context, return the failure if panic or error
+func (s *Server) runOnce(goCtx context.Context, base *Context, id int) interface{} {
+	ctx := base.Copy().(*Context)
+	ctx.SetGoContext(goCtx)
+	ctx.SetUserData(DaemonWorkerKey, id)
+
+	// only apply the last plugin for command
+	ctx.Process(s.plugins[len(s.plugins)-1:])
+
+	return ctx.failure
+}
@@ -0,0 +1,82 @@
+package pgo2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/iface"
+)
+
+type daemonPlugin struct {
+	lock   sync.Mutex
+	runs   map[int]int
+	panics int
+	block  bool
+}
+
+func (p *daemonPlugin) HandleRequest(ctx iface.IContext) {
+	id := ctx.UserData(DaemonWorkerKey, -1).(int)
+
+	p.lock.Lock()
+	p.runs[id]++
+	runs := p.runs[id]
+	p.lock.Unlock()
+
+	if runs <= p.panics {
+		panic("daemon worker panic")
+	}
+
+	if p.block {
+		<-ctx.GoContext().Done()
+	}
+}
+
+func TestServer_RunDaemon(t *testing.T) {
+	App(true)
+	plugin := &daemonPlugin{runs: make(map[int]int), panics: 2}
+	server := NewServer(map[string]interface{}{"daemonBackoff": "1ms", "daemonMaxBackoff": "2ms"})
+	server.enableAccessLog = false
+	server.AddPlugin(plugin)
+
+	server.runDaemon(context.Background(), 3)
+
+	if len(plugin.runs) != 3 {
+		t.Fatal(`worker num != 3`, plugin.runs)
+	}
+
+	for id, runs := range plugin.runs {
+		if runs != 3 {
+			t.Fatal(`worker should restart after panic`, id, runs)
+		}
+	}
+}
+
+func TestServer_RunDaemonCancel(t *testing.T) {
+	App(true)
+	plugin := &daemonPlugin{runs: make(map[int]int), block: true}
+	server := NewServer(nil)
+	server.enableAccessLog = false
+	server.AddPlugin(plugin)
+
+	goCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.runDaemon(goCtx, 2)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal(`daemon should exit after cancel`)
+	}
+
+	if len(plugin.runs) != 2 {
+		t.Fatal(`worker num != 2`, plugin.runs)
+	}
+}
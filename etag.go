@@ -0,0 +1,164 @@
+package pgo2
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pinguo/pgo2/core"
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/render"
+)
+
+const DefaultETagMaxSize = 1 << 20
+
+// ETag conditional get plugin, the response body of GET and HEAD request
+// is buffered to compute a weak ETag, and 304 is responded if the
+// If-None-Match or If-Modified-Since header matches. ETag and Last-Modified
+// headers set by action take precedence over the computed one.
+// response bigger than maxSize or flushed by action is passed through.
+// config is loaded from app.server.etag if not provided, configuration:
+// server:
+//     plugins: ["etag"]
+//     etag:
+//         maxSize: 1048576
+func NewETag(config map[string]interface{}) *ETag {
+	e := &ETag{maxSize: DefaultETagMaxSize}
+	e.pool.New = func() interface{} {
+		return &etagWrite{}
+	}
+
+	if config == nil {
+		config, _ = App().Config().Get("app.server.etag").(map[string]interface{})
+	}
+
+	core.Configure(e, config)
+
+	return e
+}
+
+type ETag struct {
+	maxSize int
+	pool    sync.Pool
+}
+
+// SetMaxSize set max size of body to buffer
+func (e *ETag) SetMaxSize(v int) {
+	e.maxSize = v
+}
+
+func (e *ETag) HandleRequest(ctx iface.IContext) {
+	method := ctx.Method()
+	if method != http.MethodGet && method != http.MethodHead {
+		return
+	}
+
+	ew := e.pool.Get().(*etagWrite)
+	ew.reset(ctx, e.maxSize)
+
+	defer func() {
+		ew.finish()
+		ew.ctx, ew.ResponseWriter = nil, nil
+		e.pool.Put(ew)
+	}()
+
+	ctx.Next()
+}
+
+// WeakETag generate weak ETag from content
+func WeakETag(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf(`W/"%x-%x"`, len(data), h.Sum64())
+}
+
+type etagWrite struct {
+	http.ResponseWriter
+	ctx     iface.IContext
+	buf     bytes.Buffer
+	maxSize int
+	pass    bool
+}
+
+func (e *etagWrite) reset(ctx iface.IContext, maxSize int) {
+	e.ResponseWriter = ctx.Output()
+	e.ctx = ctx
+	e.maxSize = maxSize
+	e.pass = false
+	e.buf.Reset()
+	ctx.SetOutput(e)
+}
+
+// passThrough write buffered data and stop buffering
+func (e *etagWrite) passThrough() {
+	if e.pass {
+		return
+	}
+
+	e.pass = true
+	if e.buf.Len() > 0 {
+		e.ResponseWriter.Write(e.buf.Bytes())
+		e.buf.Reset()
+	}
+}
+
+func (e *etagWrite) finish() {
+	if e.pass {
+		return
+	}
+
+	body := e.buf.Bytes()
+	header := e.ResponseWriter.Header()
+	if e.ctx.Status() == http.StatusOK && e.ctx.Input() != nil {
+		etag := header.Get("Etag")
+		if etag == "" {
+			etag = WeakETag(body)
+			header.Set("Etag", etag)
+		}
+
+		modTime, _ := http.ParseTime(header.Get("Last-Modified"))
+		if render.NotModified(e.ctx.Input(), etag, modTime) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			e.ResponseWriter.WriteHeader(http.StatusNotModified)
+			e.buf.Reset()
+			return
+		}
+	}
+
+	e.passThrough()
+}
+
+func (e *etagWrite) Flush() {
+	e.passThrough()
+	if flusher, ok := e.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (e *etagWrite) Write(data []byte) (n int, err error) {
+	if !e.pass && e.buf.Len()+len(data) > e.maxSize {
+		e.passThrough()
+	}
+
+	if e.pass {
+		return e.ResponseWriter.Write(data)
+	}
+
+	return e.buf.Write(data)
+}
+
+func (e *etagWrite) WriteString(data string) (n int, err error) {
+	if !e.pass && e.buf.Len()+len(data) > e.maxSize {
+		e.passThrough()
+	}
+
+	if e.pass {
+		return io.WriteString(e.ResponseWriter, data)
+	}
+
+	return e.buf.WriteString(data)
+}
@@ -0,0 +1,97 @@
+package pgo2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/logs"
+)
+
+type mockETagPlugin struct {
+	body   string
+	header map[string]string
+}
+
+func (m *mockETagPlugin) HandleRequest(ctx iface.IContext) {
+	for k, v := range m.header {
+		ctx.SetHeader(k, v)
+	}
+	ctx.End(http.StatusOK, []byte(m.body))
+}
+
+func TestNewETag(t *testing.T) {
+	var obj interface{}
+	obj = NewETag(map[string]interface{}{"maxSize": 10})
+	if _, ok := obj.(iface.IPlugin); ok == false {
+		t.FailNow()
+	}
+
+	if obj.(*ETag).maxSize != 10 {
+		t.FailNow()
+	}
+
+	App(true).Config().Set("app.server.etag", map[string]interface{}{"maxSize": 20})
+	if NewETag(nil).maxSize != 20 {
+		t.Fatal(`maxSize should be loaded from server config`)
+	}
+}
+
+func TestETag_HandleRequest(t *testing.T) {
+	App(true).Log().SetTarget(logs.TargetConsole, &mockTarget{})
+	body := `{"status":200}`
+
+	run := func(r *http.Request, maxSize int, header map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		context := &Context{}
+		context.HttpRW(false, false, r, w)
+		context.Process([]iface.IPlugin{NewETag(map[string]interface{}{"maxSize": maxSize}), &mockETagPlugin{body, header}})
+		return w
+	}
+
+	t.Run("computed", func(t *testing.T) {
+		w := run(httptest.NewRequest("GET", "/test", nil), 100, nil)
+		if w.Code != http.StatusOK || w.Body.String() != body {
+			t.FailNow()
+		}
+
+		if w.Header().Get("Etag") != WeakETag([]byte(body)) {
+			t.Fatal(w.Header().Get("Etag"))
+		}
+	})
+
+	t.Run("ifNoneMatch", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.Header.Set("If-None-Match", WeakETag([]byte(body)))
+		w := run(r, 100, nil)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatal(w.Code)
+		}
+	})
+
+	t.Run("ifModifiedSince", func(t *testing.T) {
+		modTime := time.Now().UTC().Format(http.TimeFormat)
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.Header.Set("If-Modified-Since", modTime)
+		w := run(r, 100, map[string]string{"Last-Modified": modTime})
+		if w.Code != http.StatusNotModified {
+			t.Fatal(w.Code)
+		}
+	})
+
+	t.Run("tooLarge", func(t *testing.T) {
+		w := run(httptest.NewRequest("GET", "/test", nil), 5, nil)
+		if w.Body.String() != body || w.Header().Get("Etag") != "" {
+			t.FailNow()
+		}
+	})
+
+	t.Run("post", func(t *testing.T) {
+		w := run(httptest.NewRequest("POST", "/test", nil), 100, nil)
+		if w.Body.String() != body || w.Header().Get("Etag") != "" {
+			t.FailNow()
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package pgo2
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinguo/pgo2/perror"
+	"github.com/pinguo/pgo2/util"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	argsFieldMap sync.Map // reflect.Type => []*argsField
+)
+
+// argsField field of command args struct, tags of field:
+//     flag: name of arg, default is field name with first letter lowered, "-" to skip,
+//           names of global flags are not allowed, eg. env, cmd, base
+//     usage: usage of arg for help
+//     default: default value if arg is not set
+//     required: "true" if arg is required
+//     enum: allowed values separated by comma
+type argsField struct {
+	index    int
+	name     string
+	usage    string
+	dft      string
+	required bool
+	enum     []string
+	typ      reflect.Type
+}
+
+// isArgsType check type is pointer of args struct
+func isArgsType(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !t.Implements(textUnmarshalerType)
+}
+
+// argsFields get flag fields of args struct
+func argsFields(t reflect.Type) []*argsField {
+	if v, ok := argsFieldMap.Load(t); ok {
+		return v.([]*argsField)
+	}
+
+	fields := make([]*argsField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("flag")
+		if sf.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(sf.Name[:1]) + sf.Name[1:]
+		}
+
+		if _, ok := globalParams[name]; ok {
+			panic("args flag conflicts with global flag: --" + name + " of " + t.String() + "." + sf.Name)
+		}
+
+		if !argTypeSupported(sf.Type) {
+			panic("unsupported args field type: " + sf.Type.String() + " of " + t.String() + "." + sf.Name)
+		}
+
+		field := &argsField{
+			index:    i,
+			name:     name,
+			usage:    sf.Tag.Get("usage"),
+			dft:      sf.Tag.Get("default"),
+			required: sf.Tag.Get("required") == "true",
+			typ:      sf.Type,
+		}
+
+		if enum := sf.Tag.Get("enum"); enum != "" {
+			field.enum = strings.Split(enum, ",")
+		}
+
+		fields = append(fields, field)
+	}
+
+	argsFieldMap.Store(t, fields)
+	return fields
+}
+
+// ParseArgs parse args of command to fields of struct, v must be pointer of
+// struct, fields of unset args are not changed if no default, 400 error is
+// panicked if arg is invalid, missing or not in enum.
+func ParseArgs(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if !isArgsType(rv.Type()) || rv.IsNil() {
+		panic("ParseArgs: invalid type, need pointer of struct")
+	}
+
+	parseArgs(rv)
+}
+
+func parseArgs(rv reflect.Value) {
+	invalid := make([]perror.InvalidParam, 0)
+	for _, field := range argsFields(rv.Type().Elem()) {
+		arg, has := App().Arg(field.name), App().HasArg(field.name)
+		if !has || (arg == "" && field.typ.Kind() != reflect.Bool) {
+			if field.required {
+				invalid = append(invalid, perror.InvalidParam{Name: field.name, Reason: "--" + field.name + " is required"})
+				continue
+			}
+
+			if field.dft == "" {
+				continue
+			}
+
+			arg, has = field.dft, true
+		}
+
+		if has && arg == "" && field.typ.Kind() == reflect.Bool {
+			// --flag without value
+			arg = "true"
+		}
+
+		if len(field.enum) > 0 && util.SliceSearchString(field.enum, arg) < 0 {
+			invalid = append(invalid, perror.InvalidParam{Name: field.name, Reason: "--" + field.name + " must be one of " + strings.Join(field.enum, ",")})
+			continue
+		}
+
+		fv, e := convertArg(arg, field.typ)
+		if e != nil {
+			invalid = append(invalid, perror.InvalidParam{Name: field.name, Reason: "--" + field.name + " is invalid, " + field.typ.String() + " is required"})
+			continue
+		}
+
+		rv.Elem().Field(field.index).Set(fv)
+	}
+
+	if len(invalid) > 0 {
+		err := perror.NewWarn(http.StatusBadRequest, "%s", invalid[0].Reason)
+		panic(err.WithExtension(perror.ExtInvalidParams, invalid))
+	}
+}
+
+// argTypeSupported check whether arg of type t can be converted by convertArg
+func argTypeSupported(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	} else if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return argTypeSupported(t.Elem())
+	}
+
+	return paramTypeSupported(t)
+}
+
+// convertArg convert arg to value of type t, duration and
+// slices of comma separated values are supported besides params.
+func convertArg(arg string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t == durationType:
+		d, e := time.ParseDuration(arg)
+		return reflect.ValueOf(d), e
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		items := strings.Split(arg, ",")
+		v := reflect.MakeSlice(t, 0, len(items))
+		for _, item := range items {
+			iv, e := convertArg(strings.TrimSpace(item), t.Elem())
+			if e != nil {
+				return v, e
+			}
+			v = reflect.Append(v, iv)
+		}
+		return v, nil
+	}
+
+	return convertParam(arg, t)
+}
+
+// commandArgs get args struct of command action by ParamsFlag<Action>
+// method of controller, invalid value is returned if not declared.
+// the method must return pointer of args struct kept by controller,
+// panic if nil is returned, otherwise parsed args are lost.
+func commandArgs(rv reflect.Value, actionId string) reflect.Value {
+	m := rv.MethodByName(ParamsFlagMethodPrefix + actionId)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || !isArgsType(m.Type().Out(0)) {
+		return reflect.Value{}
+	}
+
+	args := m.Call(nil)[0]
+	if args.IsNil() {
+		panic(rv.Type().String() + "." + ParamsFlagMethodPrefix + actionId + " returns nil, pointer of args struct kept by controller is required")
+	}
+
+	return args
+}
+
+// commandParams prepare params for command action, args struct returned by
+// ParamsFlag<Action> or declared as the only param of action is parsed from
+// args of command, and passed to action if it's declared as param.
+func commandParams(rv, action reflect.Value, actionId string, params, names []string) []reflect.Value {
+	args := commandArgs(rv, actionId)
+	at := action.Type()
+	if at.NumIn() == 1 && !at.IsVariadic() && isArgsType(at.In(0)) {
+		if !args.IsValid() || args.Type() != at.In(0) {
+			args = reflect.New(at.In(0).Elem())
+		}
+
+		parseArgs(args)
+		return []reflect.Value{args}
+	}
+
+	if args.IsValid() {
+		parseArgs(args)
+	}
+
+	return actionParams(action, params, names)
+}
+
+// commandArgsParams get params description of command action from args
+// struct for help, nil is returned if action has no args struct.
+func commandArgsParams(rv reflect.Value, method reflect.Method) []*ActionInfoParam {
+	var t reflect.Type
+	if m := rv.MethodByName(ParamsFlagMethodPrefix + strings.TrimPrefix(method.Name, ActionPrefix)); m.IsValid() && m.Type().NumOut() == 1 && isArgsType(m.Type().Out(0)) {
+		t = m.Type().Out(0)
+	} else if mt := method.Type; mt.NumIn() == 2 && isArgsType(mt.In(1)) {
+		t = mt.In(1)
+	} else {
+		return nil
+	}
+
+	params := make([]*ActionInfoParam, 0)
+	for _, field := range argsFields(t.Elem()) {
+		usage := field.usage
+		if len(field.enum) > 0 {
+			usage += " (one of " + strings.Join(field.enum, ",") + ")"
+		}
+
+		if field.required {
+			usage += " (required)"
+		}
+
+		params = append(params, &ActionInfoParam{
+			Name:     field.name,
+			DftValue: field.dft,
+			NameType: argsTypeName(field.typ),
+			Usage:    strings.TrimSpace(usage),
+		})
+	}
+
+	return params
+}
+
+// argsTypeName get type name of arg for help, eg. string, int, duration
+func argsTypeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return argsTypeName(t.Elem()) + "s"
+	case t.Kind() == reflect.Bool:
+		return ""
+	}
+
+	return t.Kind().String()
+}
@@ -0,0 +1,133 @@
+package pgo2
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/perror"
+)
+
+type syncArgs struct {
+	Id      int           `flag:"id" usage:"user id" required:"true"`
+	Mode    string        `enum:"full,incr" default:"full"`
+	Dry     bool          `flag:"dry-run" usage:"print only"`
+	Timeout time.Duration `default:"1m"`
+	Tags    []string
+	Skip    string `flag:"-"`
+}
+
+type syncCommand struct {
+	Controller
+	args *syncArgs
+}
+
+func (c *syncCommand) ParamsFlagRun() *syncArgs {
+	c.args = &syncArgs{Tags: []string{"a"}}
+	return c.args
+}
+
+func (c *syncCommand) ActionRun() {}
+
+func (c *syncCommand) ActionSync(args *syncArgs) {}
+
+func TestParseArgs(t *testing.T) {
+	App(true).args = map[string]string{"id": "12", "dry-run": "", "tags": "x, y", "Skip": "1"}
+
+	args := &syncArgs{Mode: "incr"}
+	ParseArgs(args)
+	want := &syncArgs{Id: 12, Mode: "full", Dry: true, Timeout: time.Minute, Tags: []string{"x", "y"}}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatal(`args != want`, args)
+	}
+
+	App().args = map[string]string{"mode": "foo", "timeout": "1"}
+	defer func() {
+		err, ok := recover().(*perror.Error)
+		if !ok || err.Status() != http.StatusBadRequest {
+			t.Fatal(`invalid args should panic 400`, err)
+		}
+
+		if invalid := err.Extensions()[perror.ExtInvalidParams].([]perror.InvalidParam); len(invalid) != 3 {
+			t.Fatal(`len(invalid) != 3`, invalid)
+		}
+	}()
+
+	ParseArgs(&syncArgs{})
+}
+
+func TestCommandParams(t *testing.T) {
+	App(true).args = map[string]string{"id": "7"}
+
+	c := &syncCommand{}
+	rv := reflect.ValueOf(c)
+	if params := commandParams(rv, rv.MethodByName("ActionRun"), "Run", nil, nil); len(params) != 0 {
+		t.Fatal(`len(params) != 0`)
+	}
+
+	if c.args.Id != 7 || c.args.Tags[0] != "a" {
+		t.Fatal(`args of ParamsFlagRun should be parsed`, c.args)
+	}
+
+	params := commandParams(rv, rv.MethodByName("ActionSync"), "Sync", nil, nil)
+	if args := params[0].Interface().(*syncArgs); args.Id != 7 || args.Mode != "full" {
+		t.Fatal(`args of action should be parsed`, args)
+	}
+}
+
+type nilArgsCommand struct {
+	Controller
+}
+
+func (c *nilArgsCommand) ParamsFlagRun() *syncArgs { return nil }
+
+func (c *nilArgsCommand) ActionRun() {}
+
+func TestCommandArgs_Invalid(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`nil args of ParamsFlagRun should panic`)
+			}
+		}()
+
+		commandArgs(reflect.ValueOf(&nilArgsCommand{}), "Run")
+	})
+
+	t.Run("global", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`args flag env should panic`)
+			}
+		}()
+
+		argsFields(reflect.TypeOf(struct{ Env string }{}))
+	})
+}
+
+func TestCommandArgsParams(t *testing.T) {
+	rv := reflect.ValueOf(&syncCommand{})
+	method, _ := rv.Type().MethodByName("ActionSync")
+	params := commandArgsParams(rv, method)
+	if len(params) != 5 {
+		t.Fatal(`len(params) != 5`)
+	}
+
+	if p := params[0]; p.Name != "id" || p.NameType != "int" || p.Usage != "user id (required)" {
+		t.Fatal(`invalid param id`, p)
+	}
+
+	if p := params[1]; p.Name != "mode" || p.DftValue != "full" || p.Usage != "(one of full,incr)" {
+		t.Fatal(`invalid param mode`, p)
+	}
+
+	if p := params[3]; p.NameType != "duration" || params[4].NameType != "strings" {
+		t.Fatal(`invalid param type`, p, params[4])
+	}
+
+	method, _ = rv.Type().MethodByName("ActionRun")
+	if params := commandArgsParams(rv, method); len(params) != 5 {
+		t.Fatal(`params of ParamsFlagRun should be used`)
+	}
+}
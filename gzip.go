@@ -74,6 +74,8 @@ func (g *gzipWrite) start() {
 		g.size = 0
 		g.writer.Reset(g.ResponseWriter)
 		g.ctx.SetHeader("Content-Encoding", "gzip")
+		// length of the compressed content is unknown
+		g.ResponseWriter.Header().Del("Content-Length")
 	}
 }
 
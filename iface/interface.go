@@ -1,6 +1,7 @@
 package iface
 
 import (
+	"context"
 	"html/template"
 	"io"
 	"net/http"
@@ -41,6 +42,10 @@ type IErrorController interface {
 	Error(status int, message string)
 }
 
+type IProblemController interface {
+	Problem(status int, message string, err error)
+}
+
 type IPlugin interface {
 	HandleRequest(ctx IContext)
 }
@@ -114,6 +119,10 @@ type IContext interface {
 	PostAll() map[string]string
 	Param(name, dft string) string
 	ParamAll() map[string]string
+	SetPathParams(params map[string]string)
+	PathParam(name, dft string) string
+	PathParamAll() map[string]string
+	GoContext() context.Context
 	ParamMap(name string) map[string]string
 	QueryMap(name string) map[string]string
 	PostMap(name string) map[string]string
@@ -152,3 +161,8 @@ type IComponentFunc func(config map[string]interface{}) (interface{}, error)
 type IAccessLogFormat interface {
 	Format(IContext) string
 }
+
+type ICacheStore interface {
+	Get(key string) []byte
+	Set(key string, data []byte, expire time.Duration) bool
+}
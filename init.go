@@ -3,6 +3,7 @@ package pgo2
 import (
 	"flag"
 	"fmt"
+	"os"
 	"reflect"
 	"regexp"
 	"strings"
@@ -23,6 +24,7 @@ const (
 	DefaultHeaderBytes     = 1 << 20
 	ControllerWebPkg       = "controller"
 	ControllerCmdPkg       = "command"
+	ModulePkg              = "modules"
 	ControllerWebType      = "Controller"
 	ControllerCmdType      = "Command"
 	ConstructMethod        = "Construct"
@@ -35,6 +37,10 @@ const (
 	MaxPlugins             = 32
 	MaxCacheObjects        = 100
 	ParamsFlagMethodPrefix = "ParamsFlag"
+	ApiDocMethodPrefix     = "ApiDoc"
+	DaemonWorkerKey        = "daemonWorker"
+	SchedulerJobKey        = "schedulerJob"
+	SchedulerTokenKey      = "schedulerToken"
 )
 
 var (
@@ -46,10 +52,15 @@ var (
 	EmptyObject    struct{}
 	restFulActions = map[string]int{"GET": 1, "POST": 1, "PUT": 1, "DELETE": 1, "PATCH": 1, "OPTIONS": 1, "HEAD": 1}
 	globalParams   = map[string]*flag.Flag{
-		"env":  {Name: "env", Usage: "set running env (optional), eg. --env=online"},
-		"cmd":  {Name: "cmd", Usage: "set running cmd (optional), eg. --cmd=/foo/bar"},
-		"base": {Name: "base", Usage: "set base path (optional), eg. --base=/base/path"},
-		"help": {Name: "help", Usage: "Displays a list of CMD controllers used (optional), eg. --help=1"},
+		"env":        {Name: "env", Usage: "set running env (optional), eg. --env=online"},
+		"cmd":        {Name: "cmd", Usage: "set running cmd (optional), eg. --cmd=/foo/bar"},
+		"base":       {Name: "base", Usage: "set base path (optional), eg. --base=/base/path"},
+		"help":       {Name: "help", Usage: "Displays a list of CMD controllers used (optional), eg. --help=1"},
+		"routes":     {Name: "routes", Usage: "Displays a list of web routes and exit (optional), eg. --routes=1 or --routes=json"},
+		"openapi":    {Name: "openapi", Usage: "Write OpenAPI document of web routes to file and exit (optional), eg. --openapi=./openapi.json"},
+		"daemon":     {Name: "daemon", Usage: "Run cmd as daemon with N workers until SIGINT/SIGTERM (optional), eg. --daemon=4"},
+		"output":     {Name: "output", Usage: "Write structured result of cmd to stdout (optional), eg. --output=json"},
+		"completion": {Name: "completion", Usage: "Write shell completion script of cmd and exit (optional), eg. --completion=bash, zsh or fish"},
 	}
 )
 
@@ -74,6 +85,10 @@ func Run() {
 	}
 	// Listen for server or start CMD
 	App().Server().Serve()
+	// Exit with code of failed CMD
+	if code := App().Server().ExitCode(); code != 0 {
+		os.Exit(code)
+	}
 }
 
 // GLogger get global logger
@@ -177,10 +192,14 @@ func cmdList(path string) {
 	fmt.Println("The --cmd path list:")
 	actionInfo := func(handler *Handler) (actionDesc, paramsMsg string) {
 		defer func() {
-			if err := recover(); err != nil {
+			if err := recover(); err != nil && paramsMsg == "" {
 				paramsMsg = path + ":不能解析参数，err:" + util.ToString(err)
 			}
 		}()
+		if desc, ok := builtinCommandDesc[handler.uri]; ok {
+			return desc[0], desc[1]
+		}
+
 		ctx := &Context{}
 		ctx.setPath(path)
 		rv := App().Container().getNew(GetAlias(handler.cPath))
@@ -191,13 +210,19 @@ func cmdList(path string) {
 
 		methodT := rv.Type().Method(handler.aId)
 
+		// params of args struct take precedence over parsed ones
+		argsParams := commandArgsParams(rv, methodT)
+		for _, v := range argsParams {
+			paramsMsg = paramsMsg + showText(v.Name, v.NameType, v.DftValue, v.Usage) + "\n"
+		}
+
 		actionInfo := NewParser().GetActionInfo(rv.Type().Elem().PkgPath(),rv.Type().Elem().Name(),methodT.Name)
 
 		if actionInfo == nil {
 			return
 		}
 		actionDesc = actionInfo.Desc
-		if actionInfo.ParamsDesc == nil {
+		if actionInfo.ParamsDesc == nil || argsParams != nil {
 			return
 		}
 
@@ -0,0 +1,208 @@
+package pgo2
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pinguo/pgo2/iface"
+)
+
+// adapterClassPrefix class name prefix of adapters, eg. github.com/pinguo/pgo2/adapter/
+var adapterClassPrefix = strings.TrimPrefix(reflect.TypeOf(Container{}).PkgPath(), VendorPrefix) + "/adapter/"
+
+// injectComponents components of application which can be injected by id
+var injectComponents = map[string]func() interface{}{
+	"config":    func() interface{} { return App().Config() },
+	"container": func() interface{} { return App().Container() },
+	"server":    func() interface{} { return App().Server() },
+	"router":    func() interface{} { return App().Router() },
+	"scheduler": func() interface{} { return App().Scheduler() },
+	"log":       func() interface{} { return App().Log() },
+	"status":    func() interface{} { return App().Status() },
+	"i18n":      func() interface{} { return App().I18n() },
+	"view":      func() interface{} { return App().View() },
+}
+
+// injectField field of class tagged with inject, tag value is name of target
+// and optional param for Prepare separated by colon, target can be:
+//     component of application, eg. `inject:"log"`
+//     adapter, eg. `inject:"redis"`, `inject:"db:dbSlave"`
+//     bound class, eg. `inject:"@app/service/UserService"`,
+//     @app is package prefix of application, eg. github.com/foo/bar
+type injectField struct {
+	index  int
+	name   string
+	typ    reflect.Type
+	tag    string
+	params []interface{}
+
+	once      sync.Once
+	err       string             // error of resolving target
+	class     string             // class name of target
+	component func() interface{} // getter of target component
+}
+
+// injectFields get fields tagged with inject of struct type
+func injectFields(rt reflect.Type) []*injectField {
+	var fields []*injectField
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+
+		if sf.PkgPath != "" || tag == "" {
+			panic("Container: invalid inject field, " + rt.String() + "." + sf.Name)
+		}
+
+		field := &injectField{index: i, name: sf.Name, typ: sf.Type, tag: tag}
+		if pos := strings.Index(tag, ":"); pos > 0 {
+			field.tag, field.params = tag[:pos], []interface{}{tag[pos+1:]}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// resolve find target of field in container, error is returned
+// if not found or target is not assignable to field.
+func (f *injectField) resolve(c *Container) string {
+	if getter, ok := injectComponents[f.tag]; ok && len(f.params) == 0 {
+		if t := reflect.TypeOf(getter()); t == nil || !t.AssignableTo(f.typ) {
+			return "Container: inject " + f.tag + " is not assignable to field " + f.name
+		}
+
+		f.component = getter
+		return ""
+	}
+
+	var err string
+	if f.class, err = c.injectClass(f.tag); err != "" {
+		return err
+	}
+
+	if !reflect.PtrTo(c.GetType(f.class)).AssignableTo(f.typ) {
+		return "Container: inject " + f.tag + " is not assignable to field " + f.name
+	}
+
+	return ""
+}
+
+// injectClass find class name of inject target, @app is replaced with package
+// prefix of application, or matched by path suffix if prefix is unknown,
+// name without slash is name of adapter, error is returned if not found.
+func (c *Container) injectClass(name string) (string, string) {
+	if strings.HasPrefix(name, "@app/") {
+		suffix := name[len("@app"):]
+		if c.appPkg != "" {
+			if class := c.appPkg + suffix; c.Has(class) {
+				return class, ""
+			}
+
+			return "", "Container: inject class not found, " + name
+		}
+
+		matches := make([]string, 0, 1)
+		for class := range c.items {
+			if strings.HasSuffix(class, suffix) {
+				matches = append(matches, class)
+			}
+		}
+
+		switch len(matches) {
+		case 0:
+			return "", "Container: inject class not found, " + name
+		case 1:
+			return matches[0], ""
+		default:
+			sort.Strings(matches)
+			return "", "Container: inject class is ambiguous, " + name + " => " + strings.Join(matches, ", ")
+		}
+	}
+
+	if !strings.Contains(name, "/") {
+		for class := range c.items {
+			if strings.HasPrefix(class, adapterClassPrefix) && strings.EqualFold(class[len(adapterClassPrefix):], name) {
+				return class, ""
+			}
+		}
+
+		return "", "Container: inject adapter not found, " + name
+	}
+
+	if class := GetAlias(name); c.Has(class) {
+		return class, ""
+	}
+
+	return "", "Container: inject class not found, " + name
+}
+
+// field resolve target of field once, panic if failed
+func (f *injectField) field(c *Container) *injectField {
+	f.once.Do(func() { f.err = f.resolve(c) })
+	if f.err != "" {
+		panic(f.err)
+	}
+
+	return f
+}
+
+// ValidateInjects resolve inject fields of all bound classes after binding
+// is complete, panic if target is not found or classes inject each other.
+func (c *Container) ValidateInjects() {
+	classes := make([]string, 0, len(c.items))
+	for class, item := range c.items {
+		for _, f := range item.injects {
+			f.field(c)
+		}
+
+		classes = append(classes, class)
+	}
+
+	// detect cycle by depth-first search, 1: visiting, 2: visited
+	sort.Strings(classes)
+	states := make(map[string]int)
+	var visit func(class string, path []string)
+	visit = func(class string, path []string) {
+		switch states[class] {
+		case 1:
+			panic("Container: inject cycle, " + strings.Join(append(path, class), " => "))
+		case 2:
+			return
+		}
+
+		states[class] = 1
+		for _, f := range c.items[class].injects {
+			if f.class != "" {
+				visit(f.class, append(path, class))
+			}
+		}
+		states[class] = 2
+	}
+
+	for _, class := range classes {
+		visit(class, nil)
+	}
+}
+
+// inject set fields of object by inject tags, objects of
+// class are fetched from container with the same context.
+func (c *Container) inject(item *bindItem, rv reflect.Value, ctx iface.IContext) {
+	for _, f := range item.injects {
+		f.field(c)
+
+		var v reflect.Value
+		if f.component != nil {
+			v = reflect.ValueOf(f.component())
+		} else {
+			v = c.Get(f.class, ctx, f.params...)
+		}
+
+		rv.Elem().Field(f.index).Set(v)
+	}
+}
@@ -0,0 +1,49 @@
+package pgo2
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ComponentKind operations of a kind of component used by built-in
+// commands, Ping is required, Get and Del are only for cache kinds,
+// Lock is only for kinds supporting distributed lock, see ComponentLock.
+type ComponentKind struct {
+	Ping func(id string) error
+	Get  func(id, key string) (interface{}, error)
+	Del  func(id, key string) (bool, error)
+	Lock ComponentLock
+}
+
+// ComponentLock acquire distributed lock of name for the run of slot with
+// lease renewal, slot is recorded as run when lock is acquired, held is
+// cancelled on lease loss, held is nil if lock is held by others or slot
+// has been run, release stops renewal and keeps the lease for keep duration.
+type ComponentLock func(ctx context.Context, id, name string, slot time.Time, ttl time.Duration) (held context.Context, token int64, release func(keep time.Duration), err error)
+
+var componentKinds = make(map[string]*ComponentKind)
+
+// RegisterComponentKind register kind of component, eg. redis,
+// kind of component is matched by prefix of component id,
+// eg. redis and redisSlave are both of kind redis.
+func RegisterComponentKind(name string, kind *ComponentKind) {
+	if name == "" || kind == nil || kind.Ping == nil {
+		panic("RegisterComponentKind: invalid kind, " + name)
+	}
+
+	componentKinds[name] = kind
+}
+
+// componentKind find kind of component by id, the longest
+// matched kind is used, eg. mongodb takes precedence over mongo.
+func componentKind(id string) (string, *ComponentKind) {
+	name, lid := "", strings.ToLower(id)
+	for k := range componentKinds {
+		if len(k) > len(name) && strings.HasPrefix(lid, strings.ToLower(k)) {
+			name = k
+		}
+	}
+
+	return name, componentKinds[name]
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pinguo/pgo2/core"
@@ -72,6 +73,24 @@ func parseLevels(str string) int {
 	return levels
 }
 
+// LevelsToString convert int levels to comma separated string,
+// eg. 0x03 => `DEBUG,INFO`
+func LevelsToString(levels int) string {
+	switch levels {
+	case LevelNone, LevelAll:
+		return LevelToString(levels)
+	}
+
+	parts := make([]string, 0, 6)
+	for level := LevelDebug; level <= LevelFatal; level <<= 1 {
+		if levels&level != 0 {
+			parts = append(parts, LevelToString(level))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
 // LogItem represent an item of log
 type LogItem struct {
 	When    time.Time
@@ -103,7 +122,7 @@ type LogItem struct {
 //             rotate: "daily"
 func NewLog(runtimePath string, config map[string]interface{}) *Log {
 	log := &Log{
-		levels:        LevelAll,
+		levels:        int32(LevelAll),
 		chanLen:       1000,
 		traceLevels:   LevelDebug,
 		flushInterval: 60 * time.Second,
@@ -118,7 +137,7 @@ func NewLog(runtimePath string, config map[string]interface{}) *Log {
 }
 
 type Log struct {
-	levels        int
+	levels        int32 // accessed atomically, levels can be changed at runtime
 	chanLen       int
 	traceLevels   int
 	flushInterval time.Duration
@@ -151,14 +170,19 @@ func (d *Log) Target(name string) ITarget {
 // SetLevels set levels to handle, default "ALL"
 func (d *Log) SetLevels(v interface{}) {
 	if _, ok := v.(string); ok {
-		d.levels = parseLevels(v.(string))
+		atomic.StoreInt32(&d.levels, int32(parseLevels(v.(string))))
 	} else if _, ok := v.(int); ok {
-		d.levels = v.(int)
+		atomic.StoreInt32(&d.levels, int32(v.(int)))
 	} else {
 		panic(fmt.Sprintf("Log: invalid levels: %v", v))
 	}
 }
 
+// Levels levels to handle
+func (d *Log) Levels() int {
+	return int(atomic.LoadInt32(&d.levels))
+}
+
 // SetChanLen set length of log channel, default 1000
 func (d *Log) SetChanLen(len int) {
 	d.chanLen = len
@@ -231,7 +255,7 @@ func (d *Log) Flush() {
 }
 
 func (d *Log) isHandling(level int) bool {
-	return level&d.levels != 0
+	return level&d.Levels() != 0
 }
 
 func (d *Log) addItem(item *LogItem) {
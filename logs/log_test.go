@@ -0,0 +1,32 @@
+package logs
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLog_SetLevels(t *testing.T) {
+	log := &Log{levels: int32(LevelAll)}
+
+	// levels are changed at runtime while logs are being written
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			log.isHandling(LevelDebug)
+		}
+	}()
+
+	log.SetLevels("WARN,ERROR")
+	wg.Wait()
+
+	if log.Levels() != LevelWarn|LevelError || log.isHandling(LevelDebug) {
+		t.Fatal(`levels mismatch`, LevelsToString(log.Levels()))
+	}
+
+	log.SetLevels(LevelNone)
+	if log.Levels() != LevelNone {
+		t.Fatal(`levels should be none`)
+	}
+}
@@ -0,0 +1,145 @@
+package pgo2
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pinguo/pgo2/config"
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/util"
+)
+
+// newModule create module of application, configuration:
+// app:
+//     modules:
+//         feedback:
+//             path: "@app/modules/feedback" // path of @module alias
+// config of module is loaded from configs/modules/<name>,
+// values not found fall back to config of application.
+func newModule(app *Application, name string) *Module {
+	path := app.config.GetString("app.modules."+name+".path", "@app/"+ModulePkg+"/"+name)
+	path, _ = filepath.Abs(GetAlias(path))
+
+	own := config.NewPath(filepath.Join(app.basePath, "configs", ModulePkg, name), app.env)
+	module := &Module{
+		name:       name,
+		path:       path,
+		own:        own,
+		config:     config.NewFallback(own, app.config),
+		components: make(map[string]interface{}),
+	}
+
+	// global alias of module, eg. @module.feedback
+	SetAlias("@module."+name, path)
+
+	return module
+}
+
+// Module the sub application mounted under prefix
+type Module struct {
+	name   string
+	path   string
+	own    config.IConfig // config of module only
+	config config.IConfig
+
+	components map[string]interface{}
+	lock       sync.RWMutex
+}
+
+// Name name of module
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Path path of module, it's the path of @module alias
+func (m *Module) Path() string {
+	return m.path
+}
+
+// Prefix mount prefix of module
+func (m *Module) Prefix() string {
+	return App().Router().modules[m.name]
+}
+
+// Config config of module with fallback to application
+func (m *Module) Config() config.IConfig {
+	return m.config
+}
+
+// GetAlias resolve path alias, @module is the path of module,
+// eg. @module/view/index.html => /path/to/modules/feedback/view/index.html
+func (m *Module) GetAlias(alias string) string {
+	if alias == "@module" || strings.HasPrefix(alias, "@module/") {
+		return m.path + alias[len("@module"):]
+	}
+
+	return GetAlias(alias)
+}
+
+// component conf by id, nil if not configured in module
+func (m *Module) componentConf(id string) map[string]interface{} {
+	conf, ok := m.own.Get("app.components." + id).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	// copy conf, config of module is shared
+	retConf := make(map[string]interface{}, len(conf))
+	for k, v := range conf {
+		if vv, ok := v.(string); ok == true {
+			retConf[k] = m.GetAlias(vv)
+		} else {
+			retConf[k] = v
+		}
+	}
+
+	return retConf
+}
+
+// Component get component by id, component configured
+// in module is created in module, otherwise falls back
+// to component of application.
+func (m *Module) Component(id string, funcName iface.IComponentFunc, params ...map[string]interface{}) interface{} {
+	m.lock.RLock()
+	obj, ok := m.components[id]
+	m.lock.RUnlock()
+	if ok {
+		return obj
+	}
+
+	confConfig := m.componentConf(id)
+	if confConfig == nil {
+		return App().Component(id, funcName, params...)
+	}
+
+	util.MapMerge(confConfig, params...)
+	obj, err := funcName(confConfig)
+	if err != nil {
+		panic("Component " + m.name + "." + id + " err:" + err.Error())
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// avoid repeated loading
+	if v, ok := m.components[id]; ok {
+		return v
+	}
+
+	m.components[id] = obj
+	return obj
+}
+
+// ContextComponent get component by id for context, component configured in
+// module of the controller handling ctx is used, otherwise falls back to
+// component of application, adapters created without context use the latter.
+func ContextComponent(ctx iface.IContext, id string, funcName iface.IComponentFunc, params ...map[string]interface{}) interface{} {
+	if ctx != nil {
+		if name := App().Router().Module(ctx.ControllerId()); name != "" {
+			return App().Module(name).Component(id, funcName, params...)
+		}
+	}
+
+	return App().Component(id, funcName, params...)
+}
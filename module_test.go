@@ -0,0 +1,67 @@
+package pgo2
+
+import (
+	"testing"
+)
+
+func TestModule_GetAlias(t *testing.T) {
+	module := &Module{name: "feedback", path: "/path/to/feedback"}
+	if v := module.GetAlias("@module/view/index.html"); v != "/path/to/feedback/view/index.html" {
+		t.Fatal(`module.GetAlias("@module/view/index.html") != /path/to/feedback/view/index.html`, v)
+	}
+
+	if v := module.GetAlias("@modulex/view"); v != "" {
+		t.Fatal(`module.GetAlias("@modulex/view") != ""`, v)
+	}
+}
+
+func TestModule_Component(t *testing.T) {
+	module := App(true).Module("feedback")
+	if module != App().Module("feedback") {
+		t.Fatal("module should be created once")
+	}
+
+	if v := GetAlias("@module.feedback"); v != module.Path() {
+		t.Fatal(`GetAlias("@module.feedback") != module.Path()`, v)
+	}
+
+	app := App().Component("moduleTestId", newComponentTest)
+	if module.Component("moduleTestId", newComponentTest) != app {
+		t.Fatal("component should fall back to application")
+	}
+
+	var conf map[string]interface{}
+	newConf := func(config map[string]interface{}) (interface{}, error) {
+		conf = config
+		return config, nil
+	}
+
+	module.own.Set("app.components.moduleTestId1", map[string]interface{}{"path": "@module/data"})
+	module.Component("moduleTestId1", newConf)
+	if conf == nil || conf["path"] != module.Path()+"/data" {
+		t.Fatal("component should be created in module", conf)
+	}
+
+	conf = nil
+	module.Component("moduleTestId1", newConf)
+	if conf != nil {
+		t.Fatal("component of module should be cached")
+	}
+
+	if v := module.own.Get("app.components.moduleTestId1.path"); v != "@module/data" {
+		t.Fatal("config of module should not be changed", v)
+	}
+
+	// adapters get component of module by context
+	App().Router().mountModule("feedback")
+	ctx := &Context{}
+	ctx.SetControllerId("/feedback/api/feedback")
+	if v, _ := ContextComponent(ctx, "moduleTestId1", newConf).(map[string]interface{}); v == nil || v["path"] != module.Path()+"/data" {
+		t.Fatal("component of module should be got by context")
+	}
+
+	ctx.SetControllerId("/api/user")
+	if ContextComponent(ctx, "moduleTestId", newComponentTest) != app {
+		t.Fatal("component of application should be got by context out of module")
+	}
+}
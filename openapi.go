@@ -0,0 +1,264 @@
+package pgo2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pinguo/pgo2/openapi"
+	"github.com/pinguo/pgo2/render"
+)
+
+// OpenAPI generate OpenAPI 3 document of web routes, paths are from
+// web handlers and declarative rules, regexp rules are not included.
+// summary, query params and methods are parsed from annotations of
+// action, eg. @ActionDesc, @Params and @Method, request and response
+// can be described by method ApiDoc<Action> of controller.
+func (r *Router) OpenAPI() *openapi.Document {
+	doc := openapi.NewDocument(App().Name(), App().Config().GetString("app.version", "1.0.0"))
+
+	uris := make([]string, 0, len(r.webHandlers))
+	for uri := range r.webHandlers {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	seen := make(map[string]bool, len(uris))
+	for _, uri := range uris {
+		handler := r.webHandlers[uri]
+		key := routeKey(handler.cId, handler.aName)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		path := r.webUrls[key]
+		for _, method := range apiMethods(handler) {
+			op := r.apiOperation(handler, method, nil)
+			op.OperationId = strings.Trim(handler.cId, "/") + "/" + handler.aName
+			if method != handler.aName {
+				op.OperationId += "/" + method
+			}
+
+			doc.AddOperation(path, method, op)
+		}
+	}
+
+	for _, rule := range r.declRules {
+		path, params := apiRulePath(rule.pattern)
+		for _, handler := range r.ruleHandlers(rule.route) {
+			for _, method := range apiMethods(handler) {
+				doc.AddOperation(path, method, r.apiOperation(handler, method, params))
+			}
+		}
+	}
+
+	return doc
+}
+
+// writeOpenAPI write OpenAPI document to file, stdout if file is empty
+func writeOpenAPI(file string) {
+	data := App().Router().OpenAPI().Json()
+	if file == "" {
+		fmt.Println(string(data))
+		return
+	}
+
+	if e := ioutil.WriteFile(file, data, 0644); e != nil {
+		panic("write openapi failed, " + e.Error())
+	}
+
+	GLogger().Info("write openapi to " + file)
+}
+
+// ruleHandlers get handlers of route of rule, all RESTful actions are returned
+func (r *Router) ruleHandlers(route string) []*Handler {
+	route = strings.ToLower(route)
+	if handler, ok := r.webHandlers[route]; ok {
+		return []*Handler{handler}
+	}
+
+	var handlers []*Handler
+	for _, method := range allowMethods {
+		if handler, ok := r.webHandlers[route+"/"+strings.ToLower(method)]; ok {
+			handlers = append(handlers, handler)
+		}
+	}
+
+	return handlers
+}
+
+// apiMethods http methods of action, classic action without
+// restriction is documented as GET and POST
+func apiMethods(handler *Handler) []string {
+	if _, ok := restFulActions[handler.aName]; ok {
+		return []string{handler.aName}
+	}
+
+	if handler.methods != nil {
+		return handler.methods
+	}
+
+	return []string{http.MethodGet, http.MethodPost}
+}
+
+// apiRulePath convert declarative pattern to path template with params,
+// eg. /user/{id:int} => /user/{id}
+func apiRulePath(pattern string) (string, []*openapi.Parameter) {
+	var params []*openapi.Parameter
+	for _, m := range reRouteParam.FindAllStringSubmatch(pattern, -1) {
+		params = append(params, &openapi.Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   openapi.TypeSchema(strings.TrimSpace(m[2])),
+		})
+	}
+
+	return reRouteParam.ReplaceAllString(pattern, "{$1}"), params
+}
+
+// apiOperation create operation of action, params are path params of rule
+func (r *Router) apiOperation(handler *Handler, method string, params []*openapi.Parameter) *openapi.Operation {
+	op := &openapi.Operation{
+		Tags:       []string{strings.Trim(handler.cId, "/")},
+		Parameters: append([]*openapi.Parameter(nil), params...),
+		Responses:  make(map[string]*openapi.Response),
+	}
+
+	var data *openapi.Schema
+	if rv := r.apiController(handler); rv.IsValid() {
+		r.apiAnnotations(op, rv, handler)
+		data = r.apiActionDoc(op, rv, handler, method)
+	}
+
+	op.Responses["200"] = &openapi.Response{
+		Description: http.StatusText(http.StatusOK),
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: apiEnvelope(data)}},
+	}
+
+	errSchema := apiEnvelope(nil)
+	errType := "application/json"
+	if r.problemDetails {
+		errSchema, errType = apiProblem(), render.ContentTypeProblem
+	}
+
+	op.Responses["default"] = &openapi.Response{
+		Description: "Error",
+		Content:     map[string]*openapi.MediaType{errType: {Schema: errSchema}},
+	}
+
+	return op
+}
+
+// apiController get new controller of handler, invalid value if not found
+func (r *Router) apiController(handler *Handler) (rv reflect.Value) {
+	defer func() { recover() }()
+	return App().Container().getNew(GetAlias(handler.cPath))
+}
+
+// apiAnnotations apply annotations of action to operation, source of
+// controller is required, panic of parsing is ignored
+func (r *Router) apiAnnotations(op *openapi.Operation, rv reflect.Value, handler *Handler) {
+	defer func() { recover() }()
+
+	aName := rv.Type().Method(handler.aId).Name
+	info := NewParser().GetActionInfo(rv.Type().Elem().PkgPath(), rv.Type().Elem().Name(), aName)
+	if info == nil {
+		return
+	}
+
+	op.Summary = info.Desc
+	for _, name := range sortedParams(info.ParamsDesc) {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        name,
+			In:          "query",
+			Description: strings.TrimSpace(info.ParamsDesc[name].Usage),
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+	}
+}
+
+// apiActionDoc apply doc returned by ApiDoc<Action> to operation,
+// schema of response data is returned
+func (r *Router) apiActionDoc(op *openapi.Operation, rv reflect.Value, handler *Handler, method string) (data *openapi.Schema) {
+	defer func() { recover() }()
+
+	m := rv.MethodByName(ApiDocMethodPrefix + handler.aName)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	ad, ok := m.Call(nil)[0].Interface().(*openapi.ActionDoc)
+	if !ok || ad == nil {
+		return nil
+	}
+
+	return applyActionDoc(op, method, ad)
+}
+
+// applyActionDoc apply doc of action to operation, schema of data is returned
+func applyActionDoc(op *openapi.Operation, method string, ad *openapi.ActionDoc) *openapi.Schema {
+	if ad.Summary != "" {
+		op.Summary = ad.Summary
+	}
+
+	if len(ad.Tags) > 0 {
+		op.Tags = ad.Tags
+	}
+
+	op.Description = ad.Description
+	op.Parameters = append(op.Parameters, openapi.QueryParams(ad.Query)...)
+	if ad.Request != nil && method != http.MethodGet && method != http.MethodHead {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: openapi.SchemaOf(ad.Request)}},
+		}
+	}
+
+	return openapi.SchemaOf(ad.Response)
+}
+
+// apiEnvelope schema of json response, eg. {"status": 200, "message": "", "data": {}}
+func apiEnvelope(data *openapi.Schema) *openapi.Schema {
+	if data == nil {
+		data = &openapi.Schema{Type: "object"}
+	}
+
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"status":  {Type: "integer", Format: "int32"},
+			"message": {Type: "string"},
+			"data":    data,
+		},
+		Required: []string{"status", "message", "data"},
+	}
+}
+
+// apiProblem schema of RFC 7807 problem details
+func apiProblem() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"type":     {Type: "string", Format: "uri"},
+			"title":    {Type: "string"},
+			"status":   {Type: "integer", Format: "int32"},
+			"detail":   {Type: "string"},
+			"instance": {Type: "string"},
+		},
+	}
+}
+
+func sortedParams(m map[string]*ActionInfoParam) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
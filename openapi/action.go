@@ -0,0 +1,53 @@
+package openapi
+
+import "sort"
+
+// ActionDoc describe action for OpenAPI, it's returned by
+// method ApiDoc<Action> of controller, eg. ApiDocView() *ActionDoc,
+// Query is struct of query params, Request is request body and
+// Response is the data of response, they can be values or pointers.
+type ActionDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Query       interface{}
+	Request     interface{}
+	Response    interface{}
+}
+
+// QueryParams create query params from fields of struct
+func QueryParams(query interface{}) []*Parameter {
+	s := SchemaOf(query)
+	if s == nil || s.Properties == nil {
+		return nil
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	params := make([]*Parameter, 0, len(s.Properties))
+	for _, name := range sortedKeys(s.Properties) {
+		fs := s.Properties[name]
+		params = append(params, &Parameter{
+			Name:        name,
+			In:          "query",
+			Description: fs.Description,
+			Required:    required[name],
+			Schema:      &Schema{Type: fs.Type, Format: fs.Format, Items: fs.Items},
+		})
+	}
+
+	return params
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
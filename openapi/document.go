@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// NewDocument create OpenAPI 3 document
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+	}
+}
+
+// Document the OpenAPI 3 document
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem operations of path, key is lower case http method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationId string               `json:"operationId,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// AddOperation add operation of path and method, existing one is kept
+func (d *Document) AddOperation(path, method string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+
+	method = strings.ToLower(method)
+	if _, ok := item[method]; !ok {
+		item[method] = op
+	}
+}
+
+// PathList sorted paths of document
+func (d *Document) PathList() []string {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Json marshal document to indented json
+func (d *Document) Json() []byte {
+	data, _ := json.MarshalIndent(d, "", "  ")
+	return data
+}
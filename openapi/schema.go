@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// TypeSchema create schema of builtin param type of route, eg. int
+func TypeSchema(typ string) *Schema {
+	switch typ {
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float":
+		return &Schema{Type: "number", Format: "double"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	return &Schema{Type: "string"}
+}
+
+// SchemaOf create schema of value, v can be value or reflect.Type,
+// exported struct fields are named by json tag, fields with
+// `validate:"required"` tag or non-pointer fields without
+// omitempty are required.
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+
+	return schemaOf(t, make(map[reflect.Type]bool))
+}
+
+func schemaOf(t reflect.Type, visited map[reflect.Type]bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	}
+
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return &Schema{Type: "string", Nullable: nullable}
+	}
+
+	s := &Schema{Nullable: nullable}
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		s.Type, s.Format = "integer", "int32"
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		s.Type, s.Format = "integer", "int64"
+	case reflect.Float32:
+		s.Type, s.Format = "number", "float"
+	case reflect.Float64:
+		s.Type, s.Format = "number", "double"
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type, s.Format = "string", "byte"
+			break
+		}
+
+		s.Type, s.Items = "array", schemaOf(t.Elem(), visited)
+	case reflect.Map:
+		s.Type, s.AdditionalProperties = "object", schemaOf(t.Elem(), visited)
+	case reflect.Struct:
+		s.Type = "object"
+		if visited[t] {
+			// recursive type is not expanded
+			break
+		}
+
+		visited[t] = true
+		s.Properties = make(map[string]*Schema)
+		structFields(t, s, visited)
+		delete(visited, t)
+	default:
+		// interface and others can be any type
+	}
+
+	return s
+}
+
+// structFields add fields of struct to schema, embedded struct is flattened
+func structFields(t reflect.Type, s *Schema, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, skip := FieldName(f, "json")
+		if skip {
+			continue
+		}
+
+		ft := f.Type
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, s, visited)
+				continue
+			}
+		}
+
+		fs := schemaOf(f.Type, visited)
+		fs.Description = f.Tag.Get("description")
+		s.Properties[name] = fs
+		if FieldRequired(f) || (!omitempty && f.Type.Kind() != reflect.Ptr) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// FieldName get name of exported field by tag, eg. `json:"name,omitempty"`,
+// skip is true for unexported field or field with "-" tag.
+func FieldName(f reflect.StructField, tag string) (name string, omitempty, skip bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false, true
+	}
+
+	parts := strings.Split(f.Tag.Get(tag), ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		return "", false, true
+	}
+
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return name, omitempty, false
+}
+
+// FieldRequired check whether field is required by validate tag
+func FieldRequired(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestBase struct {
+	Id int64 `json:"id"`
+}
+
+type schemaTestUser struct {
+	schemaTestBase
+	Name     string            `json:"name" validate:"required" description:"user name"`
+	Email    *string           `json:"email"`
+	Tags     []string          `json:"tags,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Created  time.Time         `json:"created"`
+	Extra    map[string]int    `json:"extra,omitempty"`
+	Friends  []*schemaTestUser `json:"friends,omitempty"`
+	Password string            `json:"-"`
+	internal int
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(&schemaTestUser{})
+	if s.Type != "object" {
+		t.Fatal(`s.Type != "object"`, s.Type)
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	if len(names) != 8 {
+		t.Fatal(`len(s.Properties) != 8`, names)
+	}
+
+	if !reflect.DeepEqual(s.Required, []string{"id", "name", "created"}) {
+		t.Fatal(`s.Required != [id name created]`, s.Required)
+	}
+
+	if p := s.Properties["id"]; p.Type != "integer" || p.Format != "int64" {
+		t.Fatal(`id should be int64`, p)
+	}
+
+	if p := s.Properties["name"]; p.Description != "user name" {
+		t.Fatal(`name.Description != "user name"`, p.Description)
+	}
+
+	if p := s.Properties["email"]; p.Type != "string" || !p.Nullable {
+		t.Fatal(`email should be nullable string`, p)
+	}
+
+	if p := s.Properties["avatar"]; p.Format != "byte" {
+		t.Fatal(`avatar.Format != "byte"`, p.Format)
+	}
+
+	if p := s.Properties["created"]; p.Format != "date-time" {
+		t.Fatal(`created.Format != "date-time"`, p.Format)
+	}
+
+	if p := s.Properties["extra"]; p.AdditionalProperties == nil || p.AdditionalProperties.Type != "integer" {
+		t.Fatal(`extra should be map of integer`, p)
+	}
+
+	if p := s.Properties["friends"]; p.Items == nil || p.Items.Type != "object" || p.Items.Properties != nil {
+		t.Fatal(`recursive type should not be expanded`, p.Items)
+	}
+
+	if SchemaOf(nil) != nil {
+		t.Fatal(`SchemaOf(nil) != nil`)
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	params := QueryParams(struct {
+		Page int    `json:"page" validate:"required"`
+		Sort string `json:"sort,omitempty"`
+	}{})
+
+	if len(params) != 2 || params[0].Name != "page" || !params[0].Required || params[1].Name != "sort" || params[1].Required {
+		t.Fatal(`invalid query params`, params)
+	}
+
+	if params[0].In != "query" || params[0].Schema.Type != "integer" {
+		t.Fatal(`invalid page param`, params[0])
+	}
+}
+
+func TestDocument_AddOperation(t *testing.T) {
+	d := NewDocument("test", "1.0.0")
+	op := &Operation{Summary: "first"}
+	d.AddOperation("/user", "GET", op)
+	d.AddOperation("/user", "get", &Operation{Summary: "second"})
+	d.AddOperation("/photo", "POST", &Operation{})
+
+	if d.Paths["/user"]["get"] != op {
+		t.Fatal(`existing operation should be kept`)
+	}
+
+	if !reflect.DeepEqual(d.PathList(), []string{"/photo", "/user"}) {
+		t.Fatal(`d.PathList() != [/photo /user]`, d.PathList())
+	}
+
+	if len(d.Json()) == 0 {
+		t.Fatal(`len(d.Json()) == 0`)
+	}
+}
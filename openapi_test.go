@@ -0,0 +1,72 @@
+package pgo2
+
+import (
+	"testing"
+
+	"github.com/pinguo/pgo2/openapi"
+)
+
+type mockOpenAPIController struct {
+	Controller
+}
+
+func (m *mockOpenAPIController) ActionView(id int64) {
+}
+
+func (m *mockOpenAPIController) ApiDocView() *openapi.ActionDoc {
+	return &openapi.ActionDoc{
+		Summary:  "view user",
+		Query:    struct{ Verbose bool }{},
+		Request:  struct{ Name string }{},
+		Response: struct{ Id int64 }{},
+	}
+}
+
+func TestRouter_OpenAPI(t *testing.T) {
+	App(true)
+	name := App().Container().Bind(&mockOpenAPIController{})
+	actions := App().Container().GetInfo(name).(map[string]int)
+
+	router := NewRouter(map[string]interface{}{"problemDetails": true})
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.setHandler(ControllerWebPkg, "/api/user/view", name, "/api/user", "View", actions["View"])
+	router.setHandler(ControllerWebPkg, "/api/photo/get", "controller/api/PhotoController", "/api/photo", "GET", 0)
+	router.AddRoute("/api/user/{id:int}", "/api/user/view")
+
+	doc := router.OpenAPI()
+	op := doc.Paths["/api/user/view"]["get"]
+	if op == nil || doc.Paths["/api/user/view"]["post"] == nil {
+		t.Fatal(`GET and POST /api/user/view should be documented`)
+	}
+
+	if op.Summary != "view user" || op.OperationId != "api/user/View/GET" {
+		t.Fatal(`invalid operation`, op.Summary, op.OperationId)
+	}
+
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "Verbose" || op.RequestBody != nil {
+		t.Fatal(`GET should have query params without request body`)
+	}
+
+	if post := doc.Paths["/api/user/view"]["post"]; post.RequestBody == nil {
+		t.Fatal(`POST should have request body`)
+	}
+
+	data := op.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	if data.Properties["Id"] == nil {
+		t.Fatal(`response data should be documented`)
+	}
+
+	if op.Responses["default"].Content["application/problem+json"] == nil {
+		t.Fatal(`error should be problem details`)
+	}
+
+	if op := doc.Paths["/api/photo"]["get"]; op == nil || op.OperationId != "api/photo/GET" {
+		t.Fatal(`GET /api/photo should be documented`)
+	}
+
+	rule := doc.Paths["/api/user/{id}"]["get"]
+	if rule == nil || rule.Parameters[0].In != "path" || rule.Parameters[0].Schema.Type != "integer" {
+		t.Fatal(`rule /api/user/{id} should be documented with path param`)
+	}
+}
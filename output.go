@@ -0,0 +1,119 @@
+package pgo2
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/util"
+)
+
+const (
+	OutputJson = "json"
+
+	// DefaultExitCode exit code of failed command if status is not in exit codes
+	DefaultExitCode = 1
+)
+
+// exitCoder error with specified exit code, it takes precedence over exit codes
+type exitCoder interface {
+	ExitCode() int
+}
+
+// outputJson whether command writes structured result in json, eg. --output=json
+func outputJson() bool {
+	return App().Mode() == ModeCmd && App().Arg("output") == OutputJson
+}
+
+// setFailure record panic or error of process in context
+func setFailure(ctx iface.IContext, v interface{}) {
+	if c, ok := ctx.(*Context); ok {
+		c.failure = v
+	}
+}
+
+// SetExitCodes set exit codes of failed command by status, eg. {"400": 2, "404": 3},
+// status is taken from perror.Error, 500 is used for other errors and panics.
+func (s *Server) SetExitCodes(v map[string]interface{}) {
+	for k, code := range v {
+		status, err := strconv.Atoi(k)
+		if err != nil {
+			panic("Server: invalid status of exit codes, " + k)
+		}
+
+		s.exitCodes[status] = util.ToInt(code)
+	}
+}
+
+// ExitCode get exit code of the last command, 0 if succeeded
+func (s *Server) ExitCode() int {
+	return s.exitCode
+}
+
+// failureStatus get status of panic or error
+func failureStatus(v interface{}) int {
+	if pErr, ok := asPError(v); ok {
+		return pErr.Status()
+	}
+
+	return http.StatusInternalServerError
+}
+
+// exitCodeOf get exit code of panic or error, 0 if v is nil
+func (s *Server) exitCodeOf(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+
+	if err, ok := v.(error); ok {
+		var coder exitCoder
+		if errors.As(err, &coder) {
+			return coder.ExitCode()
+		}
+	}
+
+	if code, ok := s.exitCodes[failureStatus(v)]; ok {
+		return code
+	}
+
+	return DefaultExitCode
+}
+
+// writeResult write structured result of command to stdout, eg.
+// {"success":false,"exitCode":2,"status":400,"message":"--id is required","data":{},"logId":"..."}
+func (s *Server) writeResult(ctx *Context) {
+	result := map[string]interface{}{
+		"success":  s.exitCode == 0,
+		"exitCode": s.exitCode,
+		"logId":    ctx.LogId(),
+	}
+
+	if ctx.result != nil {
+		for _, k := range []string{"status", "message", "data"} {
+			result[k] = ctx.result[k]
+		}
+	} else {
+		status, message := http.StatusOK, ""
+		if ctx.failure != nil {
+			status, message = failureStatus(ctx.failure), util.ToString(ctx.failure)
+			if pErr, ok := asPError(ctx.failure); ok {
+				message = pErr.Message()
+			}
+		}
+
+		result["status"] = status
+		result["message"] = App().Status().Text(status, "", message)
+		result["data"] = EmptyObject
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		panic("Server: json output failed, " + err.Error())
+	}
+
+	os.Stdout.Write(data)
+	os.Stdout.WriteString("\n")
+}
@@ -0,0 +1,69 @@
+package pgo2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pinguo/pgo2/perror"
+)
+
+type outputTestCommand struct {
+	Controller
+}
+
+func (c *outputTestCommand) ActionFind() (interface{}, error) {
+	return nil, perror.New(http.StatusNotFound, "user not found")
+}
+
+func (c *outputTestCommand) ActionOk() (interface{}, error) {
+	return "ok", nil
+}
+
+type exitCodeError struct{}
+
+func (e exitCodeError) Error() string { return "exit code error" }
+func (e exitCodeError) ExitCode() int { return 9 }
+
+func TestServer_exitCodeOf(t *testing.T) {
+	App(true)
+	server := NewServer(map[string]interface{}{"exitCodes": map[string]interface{}{"404": 3}})
+
+	cases := []struct {
+		v    interface{}
+		code int
+	}{
+		{nil, 0},
+		{perror.NewWarn(http.StatusBadRequest, "invalid"), 2},
+		{perror.New(http.StatusNotFound, "not found"), 3},
+		{errors.New("failed"), DefaultExitCode},
+		{"panic", DefaultExitCode},
+		{exitCodeError{}, 9},
+	}
+
+	for _, c := range cases {
+		if code := server.exitCodeOf(c.v); code != c.code {
+			t.Fatal(`exit code mismatch`, c.v, code, c.code)
+		}
+	}
+}
+
+func TestServer_commandFailure(t *testing.T) {
+	App(true)
+	App().Router().SetErrorController(App().Container().Bind(&Controller{}))
+	App().Container().bind(&outputTestCommand{}, "command/outputTestCommand")
+	App().Router().InitHandlers()
+	App().Log()
+	server := NewServer(nil)
+	server.enableAccessLog = false
+
+	failure := server.runCmd(context.Background(), "/outputTest/find", nil)
+	if status := failureStatus(failure); status != http.StatusNotFound {
+		t.Fatal(`returned error should be recorded`, failure)
+	}
+
+	if failure := server.runCmd(context.Background(), "/outputTest/ok", nil); failure != nil {
+		t.Fatal(`failure should be nil`, failure)
+	}
+}
@@ -0,0 +1,169 @@
+package pgo2
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pinguo/pgo2/perror"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// actionParams convert route params to the declared param types of
+// action, supported types are string, bool, ints, uints, floats and
+// types implement encoding.TextUnmarshaler. missing params are zero
+// values, extra params are passed to variadic param or dropped.
+// 400 error is panicked if param can not be converted, param is named
+// by names of route params if any, eg. id of /user/{id:int}.
+func actionParams(action reflect.Value, params, names []string) []reflect.Value {
+	actionType := action.Type()
+	numIn := actionType.NumIn()
+	variadic := actionType.IsVariadic()
+
+	num := numIn
+	if variadic {
+		// variadic param may be empty
+		num--
+		if len(params) > num {
+			num = len(params)
+		}
+	}
+
+	callParams := make([]reflect.Value, 0, num)
+	for i := 0; i < num; i++ {
+		var t reflect.Type
+		if variadic && i >= numIn-1 {
+			t = actionType.In(numIn - 1).Elem()
+		} else {
+			t = actionType.In(i)
+		}
+
+		param := ""
+		if i < len(params) {
+			param = params[i]
+		}
+
+		v, e := convertParam(param, t)
+		if e != nil {
+			panic(invalidParam(i, param, names, t, e))
+		}
+
+		callParams = append(callParams, v)
+	}
+
+	return callParams
+}
+
+// paramTypeSupported check whether param of type t can be converted by convertParam
+func paramTypeSupported(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) || (t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType)) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// checkActionParams check param types of action method when handler is
+// registered, args struct of command is checked by fields, panic if
+// any type is unsupported instead of failing every request.
+func checkActionParams(rt reflect.Type, method reflect.Method, cmd bool) {
+	mt := method.Type
+	if cmd {
+		if m, ok := rt.MethodByName(ParamsFlagMethodPrefix + strings.TrimPrefix(method.Name, ActionPrefix)); ok && m.Type.NumOut() == 1 && isArgsType(m.Type.Out(0)) {
+			argsFields(m.Type.Out(0).Elem())
+		}
+
+		if mt.NumIn() == 2 && !mt.IsVariadic() && isArgsType(mt.In(1)) {
+			argsFields(mt.In(1).Elem())
+			return
+		}
+	}
+
+	// the first param is receiver
+	for i := 1; i < mt.NumIn(); i++ {
+		t := mt.In(i)
+		if mt.IsVariadic() && i == mt.NumIn()-1 {
+			t = t.Elem()
+		}
+
+		if !paramTypeSupported(t) {
+			panic(fmt.Sprintf("unsupported action param type: %s of %s.%s", t, rt, method.Name))
+		}
+	}
+}
+
+// invalidParam create 400 error of param at position i, param is named
+// by name of route param, or by position if unnamed, eg. #1.
+func invalidParam(i int, param string, names []string, t reflect.Type, e error) *perror.Error {
+	name := "#" + strconv.Itoa(i+1)
+	if i < len(names) && names[i] != "" {
+		name = names[i]
+	}
+	err := perror.NewWarn(http.StatusBadRequest, "param %s %q is invalid, %s is required", name, param, t)
+	return err.WithCause(e).WithExtension(perror.ExtInvalidParams, []perror.InvalidParam{{Name: name, Reason: err.Message()}})
+}
+
+// convertParam convert param to value of type t,
+// empty param is converted to zero value
+func convertParam(param string, t reflect.Type) (reflect.Value, error) {
+	if param == "" {
+		return reflect.Zero(t), nil
+	}
+
+	if t.Implements(textUnmarshalerType) && t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem())
+		return v, v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(param))
+	}
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		v := reflect.New(t)
+		e := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(param))
+		return v.Elem(), e
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(param)
+	case reflect.Bool:
+		b, e := strconv.ParseBool(param)
+		if e != nil {
+			return v, e
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, e := strconv.ParseInt(param, 10, t.Bits())
+		if e != nil {
+			return v, e
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, e := strconv.ParseUint(param, 10, t.Bits())
+		if e != nil {
+			return v, e
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, e := strconv.ParseFloat(param, t.Bits())
+		if e != nil {
+			return v, e
+		}
+		v.SetFloat(n)
+	default:
+		panic(fmt.Sprintf("unsupported action param type: %s", t))
+	}
+
+	return v, nil
+}
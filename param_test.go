@@ -0,0 +1,122 @@
+package pgo2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/perror"
+)
+
+func TestActionParams(t *testing.T) {
+	action := reflect.ValueOf(func(id int64, name string, score float64, verbose bool, n uint8, at time.Time, ptr *time.Time, page int) {
+	})
+
+	params := actionParams(action, []string{"12", "foo", "1.5", "true", "255", "2020-01-02T03:04:05Z", "2020-01-02T03:04:05Z"}, nil)
+	if len(params) != 8 || params[0].Int() != 12 || params[1].String() != "foo" || params[2].Float() != 1.5 ||
+		!params[3].Bool() || params[4].Uint() != 255 || params[5].Interface().(time.Time).Year() != 2020 ||
+		params[6].Interface().(*time.Time).Year() != 2020 || params[7].Int() != 0 {
+		t.FailNow()
+	}
+
+	t.Run("extra", func(t *testing.T) {
+		if params := actionParams(reflect.ValueOf(func(id int) {}), []string{"1", "2"}, nil); len(params) != 1 {
+			t.FailNow()
+		}
+	})
+
+	t.Run("variadic", func(t *testing.T) {
+		params := actionParams(reflect.ValueOf(func(id int, tags ...string) {}), []string{"1", "a", "b"}, nil)
+		if len(params) != 3 || params[2].String() != "b" {
+			t.FailNow()
+		}
+
+		if params := actionParams(reflect.ValueOf(func(tags ...int) {}), nil, nil); len(params) != 0 {
+			t.FailNow()
+		}
+	})
+
+	invalid := []struct {
+		action interface{}
+		params []string
+	}{
+		{func(id int64) {}, []string{"abc"}},
+		{func(n uint8) {}, []string{"256"}},
+		{func(b bool) {}, []string{"yes"}},
+		{func(at time.Time) {}, []string{"2020"}},
+	}
+
+	for _, c := range invalid {
+		func() {
+			defer func() {
+				e, ok := recover().(*perror.Error)
+				if !ok || e.Status() != 400 || e.Cause() == nil {
+					t.Fatalf("%v should be invalid", c.params)
+				}
+			}()
+
+			actionParams(reflect.ValueOf(c.action), c.params, nil)
+		}()
+	}
+}
+
+func TestActionParams_Names(t *testing.T) {
+	action := reflect.ValueOf(func(id int64, pid int64) {})
+	for _, c := range []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"id", "pid"}, "pid"},
+		{[]string{"id", ""}, "#2"},
+		{nil, "#2"},
+	} {
+		func() {
+			defer func() {
+				e, _ := recover().(*perror.Error)
+				if e == nil {
+					t.Fatal("invalid param should panic")
+				}
+
+				invalid := e.Extensions()[perror.ExtInvalidParams].([]perror.InvalidParam)
+				if invalid[0].Name != c.want || !strings.Contains(e.Message(), "param "+c.want+" ") {
+					t.Fatalf("param should be named %s, %s", c.want, e.Message())
+				}
+			}()
+
+			actionParams(action, []string{"1", "abc"}, c.names)
+		}()
+	}
+}
+
+type paramTestController struct {
+	Controller
+}
+
+func (c *paramTestController) ActionView(id int64, at time.Time, tags ...string) {}
+
+func (c *paramTestController) ActionSave(v map[string]string) {}
+
+func (c *paramTestController) ActionSync(args *struct{ Ch chan int }) {}
+
+func TestCheckActionParams(t *testing.T) {
+	rt := reflect.TypeOf(&paramTestController{})
+	view, _ := rt.MethodByName("ActionView")
+	checkActionParams(rt, view, false)
+
+	for _, c := range []struct {
+		name string
+		cmd  bool
+	}{{"ActionSave", false}, {"ActionSync", true}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal(c.name + " should panic")
+				}
+			}()
+
+			method, _ := rt.MethodByName(c.name)
+			checkActionParams(rt, method, c.cmd)
+		}()
+	}
+}
@@ -20,6 +20,8 @@ type ActionInfo struct {
 	PkgName        string                      // 包名
 	Desc           string                      // action描述
 	ParamsDesc     map[string]*ActionInfoParam // 参数描述
+	Methods        []string                    // 允许的http方法
+	Cron           string                      // cron表达式及调度选项
 }
 
 type ActionInfoParam struct {
@@ -165,6 +167,8 @@ func (p *Parser) ActionInfo(pkgRealPath, pkgPath string) map[string][]*ActionInf
 						PkgName:        pkgName,
 						Desc:           desc,
 						ParamsDesc:     params,
+						Methods:        p.parserActionMethod(specDecl.Doc),
+						Cron:           p.parserActionCron(specDecl.Doc),
 					})
 				}
 			}
@@ -192,6 +196,60 @@ func (p *Parser) parserActionDesc(doc *ast.CommentGroup) string {
 	return ""
 }
 
+// parserActionMethod parse allowed http methods, eg. // @Method POST,PUT
+// it's honoured only if source of controller is found at InitHandlers,
+// set router.methodAnnotation to panic if source is not found.
+func (p *Parser) parserActionMethod(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+
+	keyWord := "@Method"
+	for _, v := range doc.List {
+		if pos := strings.Index(v.Text, keyWord); pos >= 0 {
+			return parseMethods(v.Text[pos+len(keyWord):])
+		}
+	}
+
+	return nil
+}
+
+// parserActionCron parse cron schedule of command action,
+// eg. // @Cron */5 * * * * overlap=skip jitter=10s timezone=UTC
+func (p *Parser) parserActionCron(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	keyWord := "@Cron"
+	for _, v := range doc.List {
+		if pos := strings.Index(v.Text, keyWord); pos >= 0 {
+			return strings.TrimSpace(v.Text[pos+len(keyWord):])
+		}
+	}
+
+	return ""
+}
+
+// parseMethods parse http methods separated by comma or space
+func parseMethods(s string) []string {
+	methods := make([]string, 0, 2)
+	for _, m := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		m = strings.ToUpper(m)
+		if _, ok := restFulActions[m]; !ok {
+			panic("Parser: invalid http method: " + m)
+		}
+
+		methods = append(methods, m)
+	}
+
+	if len(methods) == 0 {
+		return nil
+	}
+
+	return methods
+}
+
 // parserCommentParams
 func (p *Parser) parserCommentParams(doc *ast.CommentGroup) map[string]*ActionInfoParam {
 	ret := make(map[string]*ActionInfoParam)
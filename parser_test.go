@@ -0,0 +1,52 @@
+package pgo2
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestParser_parserActionMethod(t *testing.T) {
+	src := `package controller
+
+// @ActionDesc create user
+// @Method post, PUT
+func (c *UserController) ActionCreate() {}
+`
+	f, e := parser.ParseFile(token.NewFileSet(), "user.go", src, parser.ParseComments)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	methods := NewParser().parserActionMethod(f.Decls[0].(*ast.FuncDecl).Doc)
+	if !reflect.DeepEqual(methods, []string{"POST", "PUT"}) {
+		t.Fatal(methods)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("invalid method should panic")
+		}
+	}()
+
+	parseMethods("POST,FOO")
+}
+
+func TestParser_parserActionCron(t *testing.T) {
+	src := `package command
+
+// @ActionDesc sync user
+// @Cron */5 * * * * overlap=queue
+func (c *UserCommand) ActionSync() {}
+`
+	f, e := parser.ParseFile(token.NewFileSet(), "user.go", src, parser.ParseComments)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if cron := NewParser().parserActionCron(f.Decls[0].(*ast.FuncDecl).Doc); cron != "*/5 * * * * overlap=queue" {
+		t.Fatal(cron)
+	}
+}
@@ -0,0 +1,45 @@
+package perror
+
+import (
+	"net/http"
+	"sync"
+)
+
+var codes sync.Map
+
+// Code application error code, message is the default message of
+// error created by the code, it's also used as i18n key.
+type Code struct {
+	Code       int
+	HttpStatus int
+	Message    string
+}
+
+// RegisterCode register application error code with default http
+// status and message, eg. RegisterCode(11002, 403, "user.forbidden")
+func RegisterCode(code, httpStatus int, message string) {
+	codes.Store(code, Code{Code: code, HttpStatus: httpStatus, Message: message})
+}
+
+// LookupCode get registered application error code
+func LookupCode(code int) (Code, bool) {
+	if v, ok := codes.Load(code); ok {
+		return v.(Code), true
+	}
+
+	return Code{}, false
+}
+
+// httpStatus get http status of code, status of registered code is
+// used, code itself is used if it's a valid http status, otherwise 500
+func httpStatus(code int) int {
+	if c, ok := LookupCode(code); ok && c.HttpStatus > 0 {
+		return c.HttpStatus
+	}
+
+	if http.StatusText(code) != "" {
+		return code
+	}
+
+	return http.StatusInternalServerError
+}
@@ -2,19 +2,36 @@ package perror
 
 import (
 	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pinguo/pgo2/util"
 )
 
+// StackMaxDepth max depth of captured stack
+const StackMaxDepth = 32
+
 const (
 	ErrTypeWarn   = "warn"
 	ErrTypeError  = "err"
 	ErrTypeIgnore = "ignore"
 )
 
+// ExtInvalidParams extension member of invalid params, value is []InvalidParam
+const ExtInvalidParams = "invalidParams"
+
+// InvalidParam invalid request param and the reason
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
 // NewPError create new error with status and message
-// errType=warn
+// errType=err, the stack is captured if http status is 5xx
 func New(status int, msg ...interface{}) *Error {
-
-	return initError(ErrTypeError, status, msg...)
+	return initError(ErrTypeError, status, msg...).serverStack()
 }
 
 // NewWarn create new error with status and message
@@ -29,15 +46,38 @@ func NewIgnore(status int, msg ...interface{}) *Error {
 	return initError(ErrTypeIgnore, status, msg...)
 }
 
+// Wrap create new error with cause, status and message
+// errType=err, the stack is captured if http status is 5xx
+func Wrap(cause error, status int, msg ...interface{}) *Error {
+	e := initError(ErrTypeError, status, msg...).serverStack()
+	e.cause = cause
+	return e
+}
+
+// WrapWarn create new error with cause, status and message
+// errType=warn
+func WrapWarn(cause error, status int, msg ...interface{}) *Error {
+	e := initError(ErrTypeWarn, status, msg...)
+	e.cause = cause
+	return e
+}
+
 func initError(errType string, status int, msg ...interface{}) *Error {
 	message := ""
-	if len(msg) == 1 {
-		message = msg[0].(string)
-	} else if len(msg) > 1 {
-		message = fmt.Sprintf(msg[0].(string), msg[1:]...)
+	if len(msg) > 0 {
+		format, ok := msg[0].(string)
+		if !ok {
+			format = util.ToString(msg[0])
+		}
+
+		if message = format; len(msg) > 1 {
+			message = fmt.Sprintf(format, msg[1:]...)
+		}
+	} else if c, ok := LookupCode(status); ok {
+		message = c.Message
 	}
 
-	return &Error{errType, status, message}
+	return &Error{errType: errType, status: status, message: message}
 }
 
 // Exception panic as exception
@@ -45,6 +85,31 @@ type Error struct {
 	errType string
 	status  int
 	message string
+
+	typeUri    string                 // problem type URI
+	extensions map[string]interface{} // problem extension members
+
+	cause  error
+	fields map[string]interface{}
+	stack  []uintptr
+}
+
+// serverStack capture the stack of caller of New/Wrap for server
+// errors only, client errors are common and need no stack, call
+// WithStack to capture it explicitly.
+func (p *Error) serverStack() *Error {
+	if p.HttpStatus() < http.StatusInternalServerError {
+		return p
+	}
+
+	return p.withStack(4)
+}
+
+// withStack capture the stack, frames are resolved when formatted
+func (p *Error) withStack(skip int) *Error {
+	pcs := make([]uintptr, StackMaxDepth)
+	p.stack = pcs[:runtime.Callers(skip, pcs)]
+	return p
 }
 
 // ErrType return errType
@@ -77,7 +142,116 @@ func (p *Error) Message() string {
 	return p.message
 }
 
+// HttpStatus get http status, see RegisterCode
+func (p *Error) HttpStatus() int {
+	return httpStatus(p.status)
+}
+
+// WithCause set the underlying error
+func (p *Error) WithCause(err error) *Error {
+	p.cause = err
+	return p
+}
+
+// Cause get the underlying error
+func (p *Error) Cause() error {
+	return p.cause
+}
+
+// Unwrap return the underlying error for errors.Is and errors.As
+func (p *Error) Unwrap() error {
+	return p.cause
+}
+
+// Is report whether target is an *Error with the same status,
+// message is compared too if the message of target is not empty
+func (p *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return t.status == p.status && (t.message == "" || t.message == p.message)
+}
+
+// WithField add structured field, fields are written to log only
+func (p *Error) WithField(key string, v interface{}) *Error {
+	if p.fields == nil {
+		p.fields = make(map[string]interface{})
+	}
+
+	p.fields[key] = v
+	return p
+}
+
+// Fields get structured fields
+func (p *Error) Fields() map[string]interface{} {
+	return p.fields
+}
+
+// WithStack capture the stack of caller, stack of warn error is not
+// captured by default
+func (p *Error) WithStack() *Error {
+	return p.withStack(3)
+}
+
+// HasStack report whether the stack is captured
+func (p *Error) HasStack() bool {
+	return len(p.stack) > 0
+}
+
+// StackTrace format the captured stack like util.PanicTrace
+func (p *Error) StackTrace(maxDepth int, multiLine bool) string {
+	return util.StackTrace(p.stack, maxDepth, multiLine)
+}
+
+// WithType set problem type URI, eg. https://example.com/probs/out-of-credit,
+// relative URI is resolved against the problem type base of router
+func (p *Error) WithType(uri string) *Error {
+	p.typeUri = uri
+	return p
+}
+
+// Type get problem type URI
+func (p *Error) Type() string {
+	return p.typeUri
+}
+
+// WithExtension add extension member of problem details
+func (p *Error) WithExtension(name string, v interface{}) *Error {
+	if p.extensions == nil {
+		p.extensions = make(map[string]interface{})
+	}
+
+	p.extensions[name] = v
+	return p
+}
+
+// Extensions get extension members of problem details
+func (p *Error) Extensions() map[string]interface{} {
+	return p.extensions
+}
+
 // Error implement error interface
 func (p *Error) Error() string {
-	return fmt.Sprintf("errCode: %d, errMsg: %s", p.status, p.message)
+	msg := fmt.Sprintf("errCode: %d, errMsg: %s", p.status, p.message)
+	if len(p.fields) > 0 {
+		keys := make([]string, 0, len(p.fields))
+		for k := range p.fields {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		for i, k := range keys {
+			keys[i] = k + "=" + util.ToString(p.fields[k])
+		}
+
+		msg += ", fields: " + strings.Join(keys, " ")
+	}
+
+	if p.cause != nil {
+		msg += ", cause: " + p.cause.Error()
+	}
+
+	return msg
 }
@@ -1,7 +1,9 @@
 package perror
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -17,6 +19,14 @@ func TestNew(t *testing.T) {
 	if eObj.IsErrTypeErr() == false {
 		t.FailNow()
 	}
+
+	if eObj.HasStack() {
+		t.Fatal(`stack of client error should not be captured`)
+	}
+
+	if e := New(503); !e.HasStack() || !strings.Contains(e.StackTrace(1, false), "error_test.go:") {
+		t.Fatal(`stack of server error should be captured`, e.StackTrace(1, false))
+	}
 }
 
 func TestNewWarn(t *testing.T) {
@@ -68,3 +78,84 @@ func TestError_Error(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestError_WithType(t *testing.T) {
+	e := New(400).WithType("/probs/out-of-credit")
+	if e.Type() != "/probs/out-of-credit" {
+		t.FailNow()
+	}
+}
+
+func TestError_WithExtension(t *testing.T) {
+	e := New(400)
+	if e.Extensions() != nil {
+		t.FailNow()
+	}
+
+	e.WithExtension("balance", 30).WithExtension("accounts", []string{"a"})
+	if e.Extensions()["balance"] != 30 || len(e.Extensions()) != 2 {
+		t.FailNow()
+	}
+}
+
+func TestNew_NonString(t *testing.T) {
+	if e := New(500, errors.New("db down")); e.Message() != "db down" {
+		t.FailNow()
+	}
+
+	if e := New(500, 123); e.Message() != "123" {
+		t.FailNow()
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	e := Wrap(cause, 500, "failed to get user %d", 1).WithField("uid", 1)
+
+	if e.Cause() != cause || !errors.Is(e, cause) || !e.IsErrTypeErr() {
+		t.FailNow()
+	}
+
+	if e.Error() != "errCode: 500, errMsg: failed to get user 1, fields: uid=1, cause: connection refused" {
+		t.Fatal(e.Error())
+	}
+
+	if !e.HasStack() || !strings.Contains(e.StackTrace(1, false), "error_test.go:") {
+		t.Fatal(e.StackTrace(1, false))
+	}
+
+	if WrapWarn(cause, 400).HasStack() {
+		t.FailNow()
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	errNotFound := NewWarn(404)
+	err := fmt.Errorf("load: %w", NewWarn(404, "user not found"))
+
+	if !errors.Is(err, errNotFound) || errors.Is(err, NewWarn(400)) || errors.Is(err, NewWarn(404, "other")) {
+		t.FailNow()
+	}
+
+	var pErr *Error
+	if !errors.As(err, &pErr) || pErr.Message() != "user not found" {
+		t.FailNow()
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	RegisterCode(11002, 403, "user.forbidden")
+
+	e := NewWarn(11002)
+	if e.Message() != "user.forbidden" || e.HttpStatus() != 403 {
+		t.FailNow()
+	}
+
+	if c, ok := LookupCode(11002); !ok || c.HttpStatus != 403 {
+		t.FailNow()
+	}
+
+	if New(404).HttpStatus() != 404 || New(11003).HttpStatus() != 500 {
+		t.FailNow()
+	}
+}
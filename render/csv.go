@@ -0,0 +1,170 @@
+package render
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/pinguo/pgo2/util"
+)
+
+// NewCsv create csv render, row can be []string, []interface{} or
+// struct, struct fields are written in order and named by the csv
+// tag, fields tagged with "-" are skipped. if no header is set, the
+// header of struct rows is generated from the first row.
+func NewCsv(rows RowsFunc) *Csv {
+	c := &Csv{comma: ','}
+	c.init(rows)
+	return c
+}
+
+type Csv struct {
+	rowStream
+	header []string
+	comma  rune
+	bom    bool
+}
+
+// SetHeader set header line
+func (c *Csv) SetHeader(header []string) {
+	c.header = header
+}
+
+// SetComma set field delimiter, default is ','
+func (c *Csv) SetComma(comma rune) {
+	c.comma = comma
+}
+
+// SetBom write UTF-8 BOM at the beginning, it's required by Excel
+func (c *Csv) SetBom(bom bool) {
+	c.bom = bom
+}
+
+func (c *Csv) Content() []byte {
+	return c.content(c.Reader())
+}
+
+func (c *Csv) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *Csv) Reader() io.Reader {
+	return c.reader(c.writeAll)
+}
+
+func (c *Csv) Serve(w http.ResponseWriter, r *http.Request) error {
+	return c.serve(w, r, c.ContentType(), c.writeAll)
+}
+
+func (c *Csv) writeAll(w io.Writer) error {
+	var cw *csv.Writer
+	first := true
+	record := make([]string, 0, 16)
+
+	return c.write(w, func(bw *bufio.Writer, row interface{}) error {
+		if cw == nil {
+			cw = csv.NewWriter(bw)
+			cw.Comma = c.comma
+		}
+
+		if first {
+			first = false
+			if c.bom {
+				bw.WriteString("\xEF\xBB\xBF")
+			}
+
+			header := c.header
+			if header == nil {
+				header = csvStructHeader(row)
+			}
+
+			if header != nil {
+				if e := cw.Write(header); e != nil {
+					return e
+				}
+			}
+		}
+
+		var e error
+		if record, e = csvRecord(record[:0], row); e != nil {
+			return e
+		}
+
+		if e := cw.Write(record); e != nil {
+			return e
+		}
+
+		// csv.Writer has its own buffer, pass rows to bw immediately
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+// csvRecord append fields of row to record
+func csvRecord(record []string, row interface{}) ([]string, error) {
+	switch v := row.(type) {
+	case []string:
+		return append(record, v...), nil
+	case []interface{}:
+		for _, f := range v {
+			record = append(record, util.ToString(f))
+		}
+		return record, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(row))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: unsupported row type %T", row)
+	}
+
+	rt := rv.Type()
+	for i, n := 0, rt.NumField(); i < n; i++ {
+		if _, ok := csvFieldName(rt.Field(i)); ok {
+			record = append(record, util.ToString(rv.Field(i).Interface()))
+		}
+	}
+
+	return record, nil
+}
+
+// csvStructHeader get header from struct row, nil for other types
+func csvStructHeader(row interface{}) []string {
+	rt := reflect.TypeOf(row)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	header := make([]string, 0, rt.NumField())
+	for i, n := 0, rt.NumField(); i < n; i++ {
+		if name, ok := csvFieldName(rt.Field(i)); ok {
+			header = append(header, name)
+		}
+	}
+
+	return header
+}
+
+// csvFieldName get column name of struct field
+func csvFieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	name := field.Tag.Get("csv")
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
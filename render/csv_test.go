@@ -0,0 +1,72 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCsv(t *testing.T) {
+	var obj interface{}
+	obj = NewCsv(SliceRows([][]string{{"a"}}))
+	if _, ok := obj.(StreamRender); ok == false {
+		t.FailNow()
+	}
+}
+
+func TestCsv_Content(t *testing.T) {
+	t.Run("strings", func(t *testing.T) {
+		c := NewCsv(SliceRows([][]string{{"a", "b,c"}, {"1", "2"}}))
+		c.SetHeader([]string{"h1", "h2"})
+		if string(c.Content()) != "h1,h2\na,\"b,c\"\n1,2\n" {
+			t.Fatal(string(c.Content()))
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type row struct {
+			Id     int `csv:"id"`
+			Name   string
+			Ignore string `csv:"-"`
+			hidden string
+		}
+
+		c := NewCsv(SliceRows([]*row{{1, "n1", "x", "y"}, {2, "n2", "x", "y"}}))
+		c.SetComma(';')
+		if string(c.Content()) != "id;Name\n1;n1\n2;n2\n" {
+			t.Fatal(string(c.Content()))
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		defer func() {
+			if err := recover(); err != nil {
+				return
+			}
+			t.FailNow()
+		}()
+
+		NewCsv(SliceRows([]int{1})).Content()
+	})
+}
+
+func TestCsv_Serve(t *testing.T) {
+	c := NewCsv(SliceRows([][]interface{}{{1, "a"}}))
+	c.SetBom(true)
+	c.SetAttachment("export.csv")
+	w := httptest.NewRecorder()
+	if e := c.Serve(w, httptest.NewRequest("GET", "/", nil)); e != nil {
+		t.Fatal(e)
+	}
+
+	if w.Body.String() != "\xEF\xBB\xBF1,a\n" {
+		t.Fatal(w.Body.String())
+	}
+
+	if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.FailNow()
+	}
+
+	if w.Header().Get("Content-Disposition") != "attachment; filename=export.csv" {
+		t.FailNow()
+	}
+}
@@ -1,5 +1,10 @@
 package render
 
+import (
+	"io"
+	"net/http"
+)
+
 type Render interface {
 	// Data() Data to be written
 	Content() []byte
@@ -10,3 +15,11 @@ type Render interface {
 	// SetHttpCode Set The HTTP status code
 	SetHttpCode(code int)
 }
+
+type StreamRender interface {
+	Render
+	// Reader The reader of content
+	Reader() io.Reader
+	// Serve Write content to w, r is used for Range and conditional headers
+	Serve(w http.ResponseWriter, r *http.Request) error
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// NewNdJson create newline delimited json render, each row
+// is marshaled and written as one line when response is written.
+func NewNdJson(rows RowsFunc) *NdJson {
+	n := &NdJson{}
+	n.init(rows)
+	return n
+}
+
+type NdJson struct {
+	rowStream
+}
+
+func (n *NdJson) Content() []byte {
+	return n.content(n.Reader())
+}
+
+func (n *NdJson) ContentType() string {
+	return "application/x-ndjson; charset=utf-8"
+}
+
+func (n *NdJson) Reader() io.Reader {
+	return n.reader(n.writeAll)
+}
+
+func (n *NdJson) Serve(w http.ResponseWriter, r *http.Request) error {
+	return n.serve(w, r, n.ContentType(), n.writeAll)
+}
+
+func (n *NdJson) writeAll(w io.Writer) error {
+	return n.write(w, func(bw *bufio.Writer, row interface{}) error {
+		// json.Encoder appends a newline after each value
+		return json.NewEncoder(bw).Encode(row)
+	})
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewNdJson(t *testing.T) {
+	var obj interface{}
+	obj = NewNdJson(SliceRows([]int{1}))
+	if _, ok := obj.(StreamRender); ok == false {
+		t.FailNow()
+	}
+}
+
+func TestNdJson_Content(t *testing.T) {
+	n := NewNdJson(SliceRows([]map[string]int{{"a": 1}, {"a": 2}}))
+	if string(n.Content()) != "{\"a\":1}\n{\"a\":2}\n" {
+		t.Fatal(string(n.Content()))
+	}
+}
+
+func TestNdJson_ContentType(t *testing.T) {
+	n := NewNdJson(SliceRows([]int{1}))
+	if n.ContentType() != "application/x-ndjson; charset=utf-8" {
+		t.FailNow()
+	}
+}
+
+func TestNdJson_Serve(t *testing.T) {
+	t.Run("chan", func(t *testing.T) {
+		ch := make(chan int)
+		go func() {
+			for i := 0; i < 3; i++ {
+				ch <- i
+			}
+			close(ch)
+		}()
+
+		n := NewNdJson(ChanRows(ch))
+		n.SetFlush(1, time.Second)
+		w := httptest.NewRecorder()
+		if e := n.Serve(w, httptest.NewRequest("GET", "/", nil)); e != nil {
+			t.Fatal(e)
+		}
+
+		if w.Body.String() != "0\n1\n2\n" || w.Flushed == false {
+			t.Fatal(w.Body.String())
+		}
+	})
+
+	t.Run("err", func(t *testing.T) {
+		err := errors.New("stop")
+		n := NewNdJson(func(yield func(row interface{}) error) error {
+			yield(1)
+			return err
+		})
+
+		w := httptest.NewRecorder()
+		if e := n.Serve(w, httptest.NewRequest("GET", "/", nil)); e != err {
+			t.FailNow()
+		}
+
+		if w.Body.String() != "1\n" {
+			t.Fatal(w.Body.String())
+		}
+	})
+
+	t.Run("head", func(t *testing.T) {
+		n := NewNdJson(SliceRows([]int{1}))
+		w := httptest.NewRecorder()
+		n.Serve(w, httptest.NewRequest("HEAD", "/", nil))
+		if w.Body.Len() != 0 {
+			t.FailNow()
+		}
+	})
+}
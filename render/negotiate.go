@@ -0,0 +1,86 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeJson   = "application/json"
+	ContentTypeXml    = "application/xml"
+	ContentTypeNdJson = "application/x-ndjson"
+	ContentTypeCsv    = "text/csv"
+)
+
+// Negotiate choose the best media type from offers by Accept header,
+// eg. Negotiate("text/csv;q=0.9, */*;q=0.1", ContentTypeNdJson, ContentTypeCsv)
+// returns "text/csv". the first offer is returned if accept is empty,
+// empty string is returned if no offer is acceptable.
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ, bestSpec := "", 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, q := parseMediaRange(part)
+		if mediaRange == "" || q <= 0 {
+			continue
+		}
+
+		for _, offer := range offers {
+			spec := matchMediaRange(mediaRange, offer)
+			if spec < 0 {
+				continue
+			}
+
+			if q > bestQ || (q == bestQ && spec > bestSpec) {
+				best, bestQ, bestSpec = offer, q, spec
+			}
+		}
+	}
+
+	return best
+}
+
+// parseMediaRange parse one media range of Accept header
+func parseMediaRange(s string) (string, float64) {
+	params := strings.Split(s, ";")
+	mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, param := range params[1:] {
+		param = strings.TrimSpace(param)
+		if len(param) > 2 && (param[:2] == "q=" || param[:2] == "Q=") {
+			if v, e := strconv.ParseFloat(param[2:], 64); e == nil {
+				q = v
+			}
+		}
+	}
+
+	return mediaRange, q
+}
+
+// matchMediaRange check whether offer matches media range, return
+// specificity of the match, 2 for exact, 1 for type/*, 0 for */*,
+// -1 for mismatch.
+func matchMediaRange(mediaRange, offer string) int {
+	if pos := strings.IndexByte(offer, ';'); pos > 0 {
+		offer = offer[:pos]
+	}
+
+	offer = strings.ToLower(strings.TrimSpace(offer))
+	switch {
+	case mediaRange == offer:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, mediaRange[:len(mediaRange)-1]):
+		return 1
+	}
+
+	return -1
+}
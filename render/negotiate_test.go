@@ -0,0 +1,27 @@
+package render
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{ContentTypeNdJson, ContentTypeCsv}
+	cases := map[string]string{
+		"":                                 ContentTypeNdJson,
+		"text/csv":                         ContentTypeCsv,
+		"text/*":                           ContentTypeCsv,
+		"*/*":                              ContentTypeNdJson,
+		"text/csv;q=0.5, */*;q=0.8":        ContentTypeNdJson,
+		"application/json, text/csv;q=0.1": ContentTypeCsv,
+		"application/json":                 "",
+		"text/csv;q=0":                     "",
+	}
+
+	for accept, want := range cases {
+		if got := Negotiate(accept, offers...); got != want {
+			t.Fatalf("Negotiate(%q)=%q, want %q", accept, got, want)
+		}
+	}
+
+	if Negotiate("text/csv") != "" {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+	ProblemTypeBlank   = "about:blank"
+)
+
+// NewProblem create RFC 7807 problem details render,
+// the http status code is the same as status.
+func NewProblem(status int, title, detail string) *Problem {
+	return &Problem{Type: ProblemTypeBlank, Title: title, Status: status, Detail: detail, httpCode: status}
+}
+
+// Problem problem details, extensions are flattened into the top
+// level object, standard members take precedence over extensions.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+	httpCode   int
+}
+
+func (p *Problem) SetHttpCode(code int) {
+	p.httpCode = code
+}
+
+func (p *Problem) Content() []byte {
+	out := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	out["type"] = p.Type
+	out["title"] = p.Title
+	out["status"] = p.Status
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+
+	output, e := json.Marshal(out)
+	if e != nil {
+		panic(fmt.Sprintf("failed to marshal problem, %s", e))
+	}
+
+	return output
+}
+
+func (p *Problem) HttpCode() int {
+	if p.httpCode == 0 || http.StatusText(p.httpCode) == "" {
+		return http.StatusInternalServerError
+	}
+
+	return p.httpCode
+}
+
+func (p *Problem) ContentType() string {
+	return ContentTypeProblem
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestProblem_Content(t *testing.T) {
+	p := NewProblem(http.StatusBadRequest, "Bad Request", "id is required")
+	p.Instance = "abc"
+	p.Extensions = map[string]interface{}{"status": 1, "balance": 30}
+
+	out := make(map[string]interface{})
+	if e := json.Unmarshal(p.Content(), &out); e != nil {
+		t.Fatal(e)
+	}
+
+	if out["type"] != ProblemTypeBlank || out["title"] != "Bad Request" || out["detail"] != "id is required" ||
+		out["instance"] != "abc" || out["status"] != float64(400) || out["balance"] != float64(30) {
+		t.Fatalf("unexpected content: %v", out)
+	}
+
+	if p.ContentType() != ContentTypeProblem || p.HttpCode() != http.StatusBadRequest {
+		t.FailNow()
+	}
+}
+
+func TestProblem_HttpCode(t *testing.T) {
+	p := NewProblem(11002, "Custom", "")
+	if p.HttpCode() != http.StatusInternalServerError {
+		t.FailNow()
+	}
+
+	p.SetHttpCode(http.StatusOK)
+	if p.HttpCode() != http.StatusOK {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const (
+	DefaultFlushRows     = 100
+	DefaultFlushInterval = time.Second
+)
+
+// RowsFunc row iterator, yield must be called for each row in order,
+// iteration should stop and return the error if yield returns error.
+type RowsFunc func(yield func(row interface{}) error) error
+
+// ChanRows convert a receive channel to RowsFunc, rows are
+// received until the channel is closed.
+func ChanRows(ch interface{}) RowsFunc {
+	cv := reflect.ValueOf(ch)
+	if cv.Kind() != reflect.Chan || cv.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("ChanRows: param must be a receive channel")
+	}
+
+	return func(yield func(row interface{}) error) error {
+		for {
+			v, ok := cv.Recv()
+			if !ok {
+				return nil
+			}
+
+			if e := yield(v.Interface()); e != nil {
+				// drain channel to release sender
+				go func() {
+					for _, ok := cv.Recv(); ok; _, ok = cv.Recv() {
+					}
+				}()
+				return e
+			}
+		}
+	}
+}
+
+// SliceRows convert a slice to RowsFunc
+func SliceRows(slice interface{}) RowsFunc {
+	sv := reflect.ValueOf(slice)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		panic("SliceRows: param must be a slice")
+	}
+
+	return func(yield func(row interface{}) error) error {
+		for i, n := 0, sv.Len(); i < n; i++ {
+			if e := yield(sv.Index(i).Interface()); e != nil {
+				return e
+			}
+		}
+
+		return nil
+	}
+}
+
+// rowWriter write one row to w
+type rowWriter func(w *bufio.Writer, row interface{}) error
+
+// rowStream base of row based stream renders
+type rowStream struct {
+	rows          RowsFunc
+	name          string
+	disposition   string
+	httpCode      int
+	flushRows     int
+	flushInterval time.Duration
+}
+
+func (s *rowStream) init(rows RowsFunc) {
+	s.rows = rows
+	s.httpCode = http.StatusOK
+	s.flushRows = DefaultFlushRows
+	s.flushInterval = DefaultFlushInterval
+}
+
+func (s *rowStream) SetHttpCode(code int) {
+	s.httpCode = code
+}
+
+func (s *rowStream) HttpCode() int {
+	return s.httpCode
+}
+
+// SetAttachment send content as attachment with the given file name
+func (s *rowStream) SetAttachment(name string) {
+	s.name = name
+	s.disposition = DispositionAttachment
+}
+
+// SetFlush set flush policy, buffered rows are flushed to client
+// every n rows or after interval since the last flush, whichever comes first.
+func (s *rowStream) SetFlush(n int, interval time.Duration) {
+	s.flushRows = n
+	s.flushInterval = interval
+}
+
+// reader run writeAll in goroutine and return the read side of pipe
+func (s *rowStream) reader(writeAll func(w io.Writer) error) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeAll(pw))
+	}()
+
+	return pr
+}
+
+// content read all rows into memory
+func (s *rowStream) content(r io.Reader) []byte {
+	output, e := ioutil.ReadAll(r)
+	if e != nil {
+		panic("failed to read rows, " + e.Error())
+	}
+
+	return output
+}
+
+// write write all rows to w, w is flushed by the flush policy
+func (s *rowStream) write(w io.Writer, writeRow rowWriter) error {
+	bw := bufio.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+	flush := func() error {
+		if e := bw.Flush(); e != nil {
+			return e
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		return nil
+	}
+
+	num, lastFlush := 0, time.Now()
+	e := s.rows(func(row interface{}) error {
+		if e := writeRow(bw, row); e != nil {
+			return e
+		}
+
+		num++
+		if (s.flushRows > 0 && num%s.flushRows == 0) ||
+			(s.flushInterval > 0 && time.Since(lastFlush) >= s.flushInterval) {
+			lastFlush = time.Now()
+			return flush()
+		}
+
+		return nil
+	})
+
+	if fe := flush(); e == nil {
+		e = fe
+	}
+
+	return e
+}
+
+// serve write headers and all rows to w
+func (s *rowStream) serve(w http.ResponseWriter, r *http.Request, contentType string, writeAll func(w io.Writer) error) error {
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if s.disposition != "" {
+		if v := mime.FormatMediaType(s.disposition, map[string]string{"filename": s.name}); v != "" {
+			header.Set("Content-Disposition", v)
+		}
+	}
+
+	w.WriteHeader(s.httpCode)
+	if r != nil && r.Method == http.MethodHead {
+		return nil
+	}
+
+	return writeAll(w)
+}
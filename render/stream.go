@@ -0,0 +1,237 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DispositionInline     = "inline"
+	DispositionAttachment = "attachment"
+)
+
+// NewStream create stream render, content is copied from reader
+// when the response is written, nothing is buffered in memory.
+// if reader is an io.ReadSeeker, Range and If-Range are supported.
+// name is used to detect content type and as the download file name.
+func NewStream(reader io.Reader, name string) *Stream {
+	return &Stream{reader: reader, name: name, size: -1, httpCode: http.StatusOK}
+}
+
+// NewFileStream create stream render of file, modification time
+// and size are taken from the file, the file is closed after serve.
+func NewFileStream(path string) (*Stream, error) {
+	h, e := os.Open(path)
+	if e != nil {
+		return nil, e
+	}
+
+	info, e := h.Stat()
+	if e != nil {
+		h.Close()
+		return nil, e
+	}
+
+	if info.IsDir() {
+		h.Close()
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	s := NewStream(h, filepath.Base(path))
+	s.SetModTime(info.ModTime())
+	s.SetSize(info.Size())
+
+	return s, nil
+}
+
+type Stream struct {
+	reader      io.Reader
+	name        string
+	disposition string
+	contentType string
+	etag        string
+	modTime     time.Time
+	size        int64
+	httpCode    int
+}
+
+func (s *Stream) SetHttpCode(code int) {
+	s.httpCode = code
+}
+
+// SetContentType set content type, default is detected by extension of name
+func (s *Stream) SetContentType(contentType string) {
+	s.contentType = contentType
+}
+
+// SetDisposition set Content-Disposition type, inline or attachment
+func (s *Stream) SetDisposition(disposition string) {
+	s.disposition = disposition
+}
+
+// SetAttachment send content as attachment with the given file name
+func (s *Stream) SetAttachment(name string) {
+	s.name = name
+	s.disposition = DispositionAttachment
+}
+
+// SetETag set entity tag, eg. `"xyz"` or `W/"xyz"`
+func (s *Stream) SetETag(etag string) {
+	s.etag = etag
+}
+
+// SetModTime set last modification time
+func (s *Stream) SetModTime(modTime time.Time) {
+	s.modTime = modTime
+}
+
+// SetSize set content length of non-seekable reader, -1 means unknown
+func (s *Stream) SetSize(size int64) {
+	s.size = size
+}
+
+// Content read all of the reader, it should be avoided for big content
+func (s *Stream) Content() []byte {
+	if c, ok := s.reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	output, e := ioutil.ReadAll(s.reader)
+	if e != nil {
+		panic(fmt.Sprintf("failed to read stream, %s", e))
+	}
+
+	return output
+}
+
+func (s *Stream) Reader() io.Reader {
+	return s.reader
+}
+
+func (s *Stream) HttpCode() int {
+	return s.httpCode
+}
+
+func (s *Stream) ContentType() string {
+	if s.contentType != "" {
+		return s.contentType
+	}
+
+	if ctype := mime.TypeByExtension(filepath.Ext(s.name)); ctype != "" {
+		return ctype
+	}
+
+	return "application/octet-stream"
+}
+
+// Serve write content to w, seekable reader with status 200 is served
+// by http.ServeContent, which handles Range, If-Range, If-Match,
+// If-None-Match, If-Modified-Since and If-Unmodified-Since.
+// other readers are copied as is, with If-None-Match and
+// If-Modified-Since honored. reader is closed if it's an io.Closer.
+func (s *Stream) Serve(w http.ResponseWriter, r *http.Request) error {
+	if c, ok := s.reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", s.ContentType())
+	if s.disposition != "" {
+		header.Set("Content-Disposition", s.contentDisposition())
+	}
+
+	if s.etag != "" {
+		header.Set("Etag", s.etag)
+	}
+
+	rs, seekable := s.reader.(io.ReadSeeker)
+	if seekable && r != nil && s.httpCode == http.StatusOK {
+		http.ServeContent(w, r, s.name, s.modTime, rs)
+		return nil
+	}
+
+	if !s.modTime.IsZero() {
+		header.Set("Last-Modified", s.modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if r != nil && s.httpCode == http.StatusOK && NotModified(r, s.etag, s.modTime) {
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	header.Set("Accept-Ranges", "none")
+	if s.size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(s.size, 10))
+	}
+
+	w.WriteHeader(s.httpCode)
+	if r != nil && r.Method == http.MethodHead {
+		return nil
+	}
+
+	_, e := io.Copy(w, s.reader)
+	return e
+}
+
+func (s *Stream) contentDisposition() string {
+	if s.name == "" {
+		return s.disposition
+	}
+
+	if v := mime.FormatMediaType(s.disposition, map[string]string{"filename": s.name}); v != "" {
+		return v
+	}
+
+	return s.disposition
+}
+
+// NotModified check If-None-Match and If-Modified-Since headers of GET
+// or HEAD request, If-Modified-Since is ignored when If-None-Match exists.
+// etag is compared with weak comparison.
+func NotModified(r *http.Request, etag string, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, v := range strings.Split(inm, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || weakETag(v) == weakETag(etag) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || modTime.IsZero() || modTime.Unix() <= 0 {
+		return false
+	}
+
+	t, e := http.ParseTime(ims)
+	if e != nil {
+		return false
+	}
+
+	// Last-Modified has one second precision
+	return !modTime.Truncate(time.Second).After(t)
+}
+
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
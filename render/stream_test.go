@@ -0,0 +1,169 @@
+package render
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewStream(t *testing.T) {
+	var obj interface{}
+	obj = NewStream(strings.NewReader("aa"), "a.txt")
+	if _, ok := obj.(StreamRender); ok == false {
+		t.FailNow()
+	}
+}
+
+func TestNewFileStream(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "stream")
+	defer os.RemoveAll(dir)
+
+	t.Run("notExist", func(t *testing.T) {
+		if _, e := NewFileStream(filepath.Join(dir, "none.txt")); e == nil {
+			t.FailNow()
+		}
+	})
+
+	t.Run("dir", func(t *testing.T) {
+		if _, e := NewFileStream(dir); e == nil {
+			t.FailNow()
+		}
+	})
+
+	t.Run("normal", func(t *testing.T) {
+		path := filepath.Join(dir, "a.txt")
+		ioutil.WriteFile(path, []byte("aa"), 0644)
+		s, e := NewFileStream(path)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if s.size != 2 || s.modTime.IsZero() {
+			t.FailNow()
+		}
+
+		if bytes.Equal(s.Content(), []byte("aa")) == false {
+			t.FailNow()
+		}
+	})
+}
+
+func TestStream_ContentType(t *testing.T) {
+	s := NewStream(strings.NewReader("aa"), "a.json")
+	if s.ContentType() != "application/json" {
+		t.Fatal(`s.ContentType() != "application/json"`)
+	}
+
+	s = NewStream(strings.NewReader("aa"), "")
+	if s.ContentType() != "application/octet-stream" {
+		t.Fatal(`s.ContentType() != "application/octet-stream"`)
+	}
+
+	s.SetContentType("text/csv")
+	if s.ContentType() != "text/csv" {
+		t.Fatal(`s.ContentType() != "text/csv"`)
+	}
+}
+
+func TestStream_HttpCode(t *testing.T) {
+	s := NewStream(strings.NewReader("aa"), "a.txt")
+	if s.HttpCode() != 200 {
+		t.FailNow()
+	}
+
+	s.SetHttpCode(100)
+	if s.HttpCode() != 100 {
+		t.FailNow()
+	}
+}
+
+func TestStream_Serve(t *testing.T) {
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("range", func(t *testing.T) {
+		s := NewStream(strings.NewReader("0123456789"), "a.txt")
+		s.SetModTime(modTime)
+		r := httptest.NewRequest("GET", "/a.txt", nil)
+		r.Header.Set("Range", "bytes=0-1")
+		w := httptest.NewRecorder()
+		s.Serve(w, r)
+		if w.Code != http.StatusPartialContent || w.Body.String() != "01" {
+			t.Fatal(w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("ifRange", func(t *testing.T) {
+		s := NewStream(strings.NewReader("0123456789"), "a.txt")
+		s.SetETag(`"v2"`)
+		r := httptest.NewRequest("GET", "/a.txt", nil)
+		r.Header.Set("Range", "bytes=0-1")
+		r.Header.Set("If-Range", `"v1"`)
+		w := httptest.NewRecorder()
+		s.Serve(w, r)
+		if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+			t.Fatal(w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("notModified", func(t *testing.T) {
+		s := NewStream(bytes.NewBufferString("0123456789"), "a.txt")
+		s.SetETag(`"v1"`)
+		r := httptest.NewRequest("GET", "/a.txt", nil)
+		r.Header.Set("If-None-Match", `W/"v1"`)
+		w := httptest.NewRecorder()
+		s.Serve(w, r)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatal(w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("attachment", func(t *testing.T) {
+		s := NewStream(bytes.NewBufferString("0123456789"), "a.txt")
+		s.SetAttachment("报表.csv")
+		s.SetSize(10)
+		w := httptest.NewRecorder()
+		s.Serve(w, httptest.NewRequest("GET", "/a.txt", nil))
+		if w.Header().Get("Content-Length") != "10" {
+			t.FailNow()
+		}
+
+		if strings.Index(w.Header().Get("Content-Disposition"), "attachment; filename*=utf-8''") != 0 {
+			t.Fatal(w.Header().Get("Content-Disposition"))
+		}
+	})
+}
+
+func TestNotModified(t *testing.T) {
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	if NotModified(r, "", modTime) == false {
+		t.Fatal("If-Modified-Since equal")
+	}
+
+	if NotModified(r, "", modTime.Add(time.Hour)) {
+		t.Fatal("If-Modified-Since older")
+	}
+
+	r.Header.Set("If-None-Match", `"a", "b"`)
+	if NotModified(r, `W/"b"`, time.Time{}) == false {
+		t.Fatal("If-None-Match match")
+	}
+
+	if NotModified(r, `"c"`, modTime) {
+		t.Fatal("If-None-Match take precedence")
+	}
+
+	r = httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("If-None-Match", "*")
+	if NotModified(r, `"c"`, modTime) {
+		t.Fatal("POST")
+	}
+}
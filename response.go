@@ -72,3 +72,30 @@ func (r *Response) ReadFrom(src io.Reader) (n int64, e error) {
 	r.size += int(n)
 	return
 }
+
+// Flush send buffered data to client, it implements http.Flusher.
+func (r *Response) Flush() {
+	r.finish()
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// headWrite discard body of HEAD request, headers are kept
+type headWrite struct {
+	http.ResponseWriter
+}
+
+func (h *headWrite) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (h *headWrite) WriteString(s string) (int, error) {
+	return len(s), nil
+}
+
+func (h *headWrite) Flush() {
+	if flusher, ok := h.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -71,3 +71,24 @@ func TestResponse_ReadFrom(t *testing.T) {
 	}
 
 }
+
+func TestResponse_Flush(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := &Response{}
+	r.reset(w)
+	r.WriteHeader(201)
+	r.Flush()
+
+	if w.Code != 201 || w.Flushed == false {
+		t.FailNow()
+	}
+}
+
+func TestHeadWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	h := &headWrite{w}
+	h.Header().Set("Content-Type", "text/plain")
+	if n, _ := h.Write([]byte("abc")); n != 3 || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "text/plain" {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,263 @@
+package pgo2
+
+import (
+	"strings"
+)
+
+// checkers of builtin param types, param value is never empty
+// and never contains slash except for the path type.
+var routeParamCheckers = map[string]func(string) bool{
+	"":      func(string) bool { return true },
+	"int":   func(s string) bool { return isDigits(strings.TrimPrefix(s, "-")) },
+	"uint":  isDigits,
+	"float": isFloat,
+	"alpha": isAlpha,
+	"word":  isWord,
+	"uuid":  isUuid,
+	"path":  func(string) bool { return true },
+}
+
+// routeParam param of route tree node
+type routeParam struct {
+	name  string
+	typ   string
+	check func(string) bool
+}
+
+// routeNode node of compressed radix tree for declarative routes,
+// static children are matched first, then params in insertion
+// order, then catch-all, matching backtracks on failure.
+type routeNode struct {
+	prefix   string
+	indices  string
+	children []*routeNode
+	params   []*routeNode
+	catchAll *routeNode
+	param    *routeParam
+	rule     *routeRule
+}
+
+// routeToken static part or param of declarative pattern
+type routeToken struct {
+	static string
+	param  *routeParam
+}
+
+// parseTreeRoute split declarative pattern into tokens, false is returned
+// if pattern can not be handled by route tree, eg. param is not a whole
+// segment or type of param is a regexp.
+func parseTreeRoute(pattern string) ([]routeToken, bool) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, false
+	}
+
+	tokens := make([]routeToken, 0, 4)
+	last := 0
+	for _, loc := range reRouteParam.FindAllStringSubmatchIndex(pattern, -1) {
+		if loc[0] == 0 || pattern[loc[0]-1] != '/' || (loc[1] < len(pattern) && pattern[loc[1]] != '/') {
+			return nil, false
+		}
+
+		name, typ := pattern[loc[2]:loc[3]], ""
+		if loc[4] != -1 {
+			typ = strings.TrimSpace(pattern[loc[4]:loc[5]])
+		}
+
+		check, ok := routeParamCheckers[typ]
+		if !ok || (typ == "path" && loc[1] != len(pattern)) {
+			return nil, false
+		}
+
+		tokens = append(tokens, routeToken{static: pattern[last:loc[0]]})
+		tokens = append(tokens, routeToken{param: &routeParam{name: name, typ: typ, check: check}})
+		last = loc[1]
+	}
+
+	if last < len(pattern) {
+		tokens = append(tokens, routeToken{static: pattern[last:]})
+	}
+
+	return tokens, true
+}
+
+// add add rule of tokens, the first added rule takes precedence
+// if patterns are the same.
+func (n *routeNode) add(tokens []routeToken, rule *routeRule) {
+	node := n
+	for _, token := range tokens {
+		if token.param == nil {
+			node = node.addStatic(token.static)
+		} else {
+			node = node.addParam(token.param)
+		}
+	}
+
+	if node.rule == nil {
+		node.rule = rule
+	}
+}
+
+func (n *routeNode) addStatic(s string) *routeNode {
+	if s == "" {
+		return n
+	}
+
+	pos := strings.IndexByte(n.indices, s[0])
+	if pos == -1 {
+		child := &routeNode{prefix: s}
+		n.indices += s[:1]
+		n.children = append(n.children, child)
+		return child
+	}
+
+	child := n.children[pos]
+	l := commonPrefix(child.prefix, s)
+	if l < len(child.prefix) {
+		// split child at the common prefix
+		split := *child
+		split.prefix = child.prefix[l:]
+		*child = routeNode{prefix: child.prefix[:l], indices: split.prefix[:1], children: []*routeNode{&split}}
+	}
+
+	return child.addStatic(s[l:])
+}
+
+func (n *routeNode) addParam(param *routeParam) *routeNode {
+	if param.typ == "path" {
+		if n.catchAll == nil {
+			n.catchAll = &routeNode{param: param}
+		}
+
+		return n.catchAll
+	}
+
+	for _, child := range n.params {
+		if child.param.name == param.name && child.param.typ == param.typ {
+			return child
+		}
+	}
+
+	child := &routeNode{param: param}
+	n.params = append(n.params, child)
+	return child
+}
+
+// match match path after the prefix of node, values of params are
+// appended to values.
+func (n *routeNode) match(path string, values []string) (*routeRule, []string) {
+	if path == "" {
+		if n.rule != nil {
+			return n.rule, values
+		}
+
+		return nil, nil
+	}
+
+	if pos := strings.IndexByte(n.indices, path[0]); pos != -1 {
+		child := n.children[pos]
+		if strings.HasPrefix(path, child.prefix) {
+			if rule, v := child.match(path[len(child.prefix):], values); rule != nil {
+				return rule, v
+			}
+		}
+	}
+
+	if len(n.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end == -1 {
+			end = len(path)
+		}
+
+		if seg := path[:end]; seg != "" {
+			for _, child := range n.params {
+				if !child.param.check(seg) {
+					continue
+				}
+
+				if rule, v := child.match(path[end:], append(values, seg)); rule != nil {
+					return rule, v
+				}
+			}
+		}
+	}
+
+	if n.catchAll != nil && n.catchAll.rule != nil {
+		return n.catchAll.rule, append(values, path)
+	}
+
+	return nil, nil
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isFloat(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if pos := strings.IndexByte(s, '.'); pos != -1 {
+		return isDigits(s[:pos]) && isDigits(s[pos+1:])
+	}
+
+	return isDigits(s)
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWord(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c != '_' && (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUuid(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'f') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
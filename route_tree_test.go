@@ -0,0 +1,78 @@
+package pgo2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTreeRoute(t *testing.T) {
+	cases := map[string]bool{
+		"/user/{id:int}/photo/{pid}": true,
+		"/static/{file:path}":        true,
+		"/static/{file:path}/x":      false,
+		"/lang/{code}.json":          false,
+		"/lang/{code:[a-z]{2}}":      false,
+		"user/{id}":                  false,
+	}
+
+	for pattern, want := range cases {
+		if _, ok := parseTreeRoute(pattern); ok != want {
+			t.Fatalf("parseTreeRoute(%q)=%v, want %v", pattern, ok, want)
+		}
+	}
+}
+
+func TestRouteNode_Match(t *testing.T) {
+	tree := &routeNode{}
+	patterns := []string{
+		"/user/{id:int}",
+		"/user/me",
+		"/user/{name}",
+		"/user/{id:int}/photo/{pid:uuid}",
+		"/users/{id:uint}/profile",
+		"/static/{file:path}",
+		"/static/index",
+		"/user/{id:int}",
+	}
+
+	for i, pattern := range patterns {
+		tokens, ok := parseTreeRoute(pattern)
+		if !ok {
+			t.Fatalf("parseTreeRoute(%q) failed", pattern)
+		}
+
+		tree.add(tokens, &routeRule{pattern: pattern, route: patterns[i]})
+	}
+
+	cases := []struct {
+		path    string
+		pattern string
+		values  []string
+	}{
+		{"/user/12", "/user/{id:int}", []string{"12"}},
+		{"/user/me", "/user/me", nil},
+		{"/user/mike", "/user/{name}", []string{"mike"}},
+		{"/user/-3/photo/0f8fad5b-d9cb-469f-a165-70867728950e", "/user/{id:int}/photo/{pid:uuid}", []string{"-3", "0f8fad5b-d9cb-469f-a165-70867728950e"}},
+		{"/user/3/photo/abc", "", nil},
+		{"/users/3/profile", "/users/{id:uint}/profile", []string{"3"}},
+		{"/users/-3/profile", "", nil},
+		{"/static/js/app.js", "/static/{file:path}", []string{"js/app.js"}},
+		{"/static/index", "/static/index", nil},
+		{"/static", "", nil},
+		{"/use", "", nil},
+	}
+
+	for _, c := range cases {
+		rule, values := tree.match(c.path, nil)
+		if c.pattern == "" {
+			if rule != nil {
+				t.Fatalf("%s should not match, got %s", c.path, rule.pattern)
+			}
+			continue
+		}
+
+		if rule == nil || rule.pattern != c.pattern || !reflect.DeepEqual(values, c.values) {
+			t.Fatalf("match(%q)=%v %v, want %s %v", c.path, rule, values, c.pattern, c.values)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package pgo2
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/pinguo/pgo2/core"
 	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/render"
 	"github.com/pinguo/pgo2/util"
 )
 
@@ -31,19 +35,88 @@ type routeRule struct {
 	rePat   *regexp.Regexp
 	pattern string
 	route   string
+	names   []string // names of captured groups, empty for unnamed group
+	order   int      // order of rule in config
+}
+
+// named param of declarative route: {name} or {name:type}
+var reRouteParam = regexp.MustCompile(`\{([a-zA-Z_]\w*)(?::([^{}]*(?:\{[^{}]*\}[^{}]*)*))?\}`)
+
+// pattern of builtin param types, other types are used as regexp
+var routeParamTypes = map[string]string{
+	"":      `[^/]+`,
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"float": `-?[0-9]+(?:\.[0-9]+)?`,
+	"alpha": `[a-zA-Z]+`,
+	"word":  `\w+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"path":  `.+`,
+}
+
+// isDeclarativeRoute check whether pattern is declarative, eg. /user/{id:int}
+func isDeclarativeRoute(pattern string) bool {
+	return !strings.HasPrefix(pattern, "^") && reRouteParam.MatchString(pattern)
+}
+
+// compileRoute convert declarative pattern to regexp,
+// eg. /user/{id:int}/photo/{pid} => ^/user/(?P<id>-?[0-9]+)/photo/(?P<pid>[^/]+)$
+func compileRoute(pattern string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('^')
+
+	last := 0
+	for _, loc := range reRouteParam.FindAllStringSubmatchIndex(pattern, -1) {
+		buf.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+
+		name, typ := pattern[loc[2]:loc[3]], ""
+		if loc[4] != -1 {
+			typ = strings.TrimSpace(pattern[loc[4]:loc[5]])
+		}
+
+		expr, ok := routeParamTypes[typ]
+		if !ok {
+			expr = typ
+		}
+
+		buf.WriteString("(?P<" + name + ">" + expr + ")")
+		last = loc[1]
+	}
+
+	buf.WriteString(regexp.QuoteMeta(pattern[last:]))
+	buf.WriteByte('$')
+	return buf.String()
 }
 
 // Router the router component, configuration:
 // router:
 //     httpStatus:true // Whether to override the HTTP status code
+//     problemDetails: true // Whether to output errors as application/problem+json
+//     problemTypeBase: "https://example.com/probs/" // base of relative problem type
+//     versionHeader: "X-API-Version" // request header of api version
+//     versionMedia: "vnd.app" // eg. Accept: application/vnd.app.v2+json
+//     versionFallback: true // fall back to the nearest lower version
+//     methodAnnotation: false // require source of controllers for @Method, it's honoured if source is found anyway
+//     modules: // mount prefix of modules under modules/<name>, default /<name>
+//         feedback: "/fb"
 //     rules:
 //         - "^/foo/all$ => /foo/index"
 //         - "^/api/user/(\d+)$ => /api/user"
+//         - "/api/user/{id:int}/photo/{pid} => /api/photo"
+//     hosts:
+//         - host: "api.example.com"
+//           prefix: "/api"
+//         - host: "*.admin.example.com"
+//           prefix: "/admin"
+//           errorController: "@pgo/controller/admin/ErrorController"
+//           plugins: ["gzip"]
 func NewRouter(config map[string]interface{}) *Router {
 	router := &Router{}
 	router.reFmt = regexp.MustCompile(`([/-][a-z])`)
 	router.rePathFmt = regexp.MustCompile(`([A-Z])`)
 	router.rules = make([]routeRule, 0, 10)
+	router.versionHeader = DefaultVersionHeader
+	router.versionFallback = true
 
 	core.Configure(router, config)
 
@@ -51,24 +124,55 @@ func NewRouter(config map[string]interface{}) *Router {
 }
 
 type Handler struct {
-	uri   string
-	cPath string
-	cId   string
-	aName string
-	aId   int
+	uri     string
+	cPath   string
+	cId     string
+	aName   string
+	aId     int
+	methods []string // allowed http methods, nil means any
+}
+
+// allow check whether method is allowed, HEAD is allowed if GET is allowed
+func (h *Handler) allow(method string) bool {
+	if h.methods == nil {
+		return true
+	}
+
+	for _, m := range h.methods {
+		if m == method || (method == http.MethodHead && m == http.MethodGet) {
+			return true
+		}
+	}
+
+	return false
 }
 
 type Router struct {
 	reFmt     *regexp.Regexp
 	rePathFmt *regexp.Regexp
 	rules     []routeRule
+	tree      *routeNode // declarative rules
 
 	webHandlers map[string]*Handler
 	cmdHandlers map[string]*Handler
-	modules     []string
+	webUrls     map[string]string     // preferred uri of web action for reverse routing
+	declRules   []*routeRule          // declarative rules for reverse routing
+	overridden  map[string][]*Handler // handlers overridden by others with the same uri
+	modules     map[string]string     // mount prefix of module, name => prefix
 
 	errorController string
+	hosts           *vhosts
 	httpStatus      bool // Whether to override the HTTP status code
+	problemDetails  bool
+	problemTypeBase string
+
+	versions        []int // sorted versions of controllers, eg. controller/v2
+	versionHeader   string
+	versionMedia    *regexp.Regexp
+	versionFallback bool
+
+	numRules         int  // number of added rules
+	methodAnnotation bool // resolve @Method annotation of actions
 }
 
 var rePath = strings.NewReplacer("/"+ControllerCmdPkg+"/", "/", "/"+ControllerWebPkg+"/", "/", ControllerCmdType, "", ControllerWebType, "")
@@ -77,11 +181,139 @@ func (r *Router) SetHttpStatus(v bool) {
 	r.httpStatus = v
 }
 
+// SetProblemDetails output errors as RFC 7807 problem details
+func (r *Router) SetProblemDetails(v bool) {
+	r.problemDetails = v
+}
+
+// SetProblemTypeBase set base URI of relative problem type
+func (r *Router) SetProblemTypeBase(v string) {
+	r.problemTypeBase = v
+}
+
+// SetMethodAnnotation require @Method annotation of actions to be read, @Method
+// is honoured whenever source of controllers is found at InitHandlers, if set,
+// panic if source is not found instead of ignoring @Method silently.
+func (r *Router) SetMethodAnnotation(v bool) {
+	r.methodAnnotation = v
+}
+
+// ProblemType resolve problem type of perror, relative type is
+// joined with problem type base, empty type means about:blank
+func (r *Router) ProblemType(typeUri string) string {
+	if typeUri == "" {
+		return render.ProblemTypeBlank
+	}
+
+	if r.problemTypeBase == "" || strings.Contains(typeUri, ":") {
+		return typeUri
+	}
+
+	return strings.TrimRight(r.problemTypeBase, "/") + "/" + strings.TrimLeft(typeUri, "/")
+}
+
 func (r *Router) SetErrorController(v string) {
 	r.errorController = v
 }
 
+// SetHosts set virtual hosts, host is exact name or wildcard like
+// *.example.com, request path of host is resolved under the prefix,
+// eg. /user/view of api.example.com is resolved as /api/user/view,
+// the prefix is reserved and not accessible from other hosts.
+// errorController and plugins of host override the default ones.
+func (r *Router) SetHosts(list []interface{}) {
+	r.hosts = newVhosts(list)
+}
+
+// host get virtual host of request, nil for cmd mode or no match
+func (r *Router) host(ctx iface.IContext) *vhost {
+	if r.hosts == nil || ctx.Input() == nil {
+		return nil
+	}
+
+	return r.hosts.match(ctx.Input().Host)
+}
+
+// hostPath get the path to resolve of virtual host, false is
+// returned if path is reserved by other hosts.
+func (r *Router) hostPath(vh *vhost, path string) (string, bool) {
+	if vh == nil || vh.prefix == "" {
+		return path, r.hosts == nil || !r.hosts.reserved(util.CleanPath(path))
+	}
+
+	if path == "" || path == "/" {
+		return vh.prefix + "/" + DefaultControllerPath, true
+	}
+
+	return vh.prefix + util.CleanPath(path), true
+}
+
+// SetModules set mount prefix of modules, eg. {"feedback": "/fb"},
+// module not set is mounted under /<name>, empty prefix means root.
+func (r *Router) SetModules(v map[string]interface{}) {
+	r.modules = make(map[string]string, len(v))
+	for name, prefix := range v {
+		r.modules[name] = r.modulePrefix(util.ToString(prefix))
+	}
+}
+
+// modulePrefix clean mount prefix of module
+func (r *Router) modulePrefix(prefix string) string {
+	return strings.TrimRight(util.CleanPath(prefix), "/")
+}
+
+// mountModule mount module under the default prefix if not set
+func (r *Router) mountModule(name string) {
+	if r.modules == nil {
+		r.modules = make(map[string]string)
+	}
+
+	if _, ok := r.modules[name]; !ok {
+		r.modules[name] = r.modulePrefix(name)
+	}
+}
+
+// Module get name of module which controller belongs to,
+// controllerId is the same as ctx.ControllerId(), eg. /fb/api/feedback,
+// empty string is returned if controller is not in any module,
+// module mounted under root can not be resolved by controllerId.
+func (r *Router) Module(controllerId string) string {
+	module, length := "", 0
+	for name, prefix := range r.modules {
+		if len(prefix) > length && strings.HasPrefix(controllerId, prefix+"/") {
+			module, length = name, len(prefix)
+		}
+	}
+
+	return module
+}
+
+// controllerPath get path of controller, controller of module is
+// mounted under prefix of module, eg. modules/feedback/controller/api/FeedbackController => /feedback/api/Feedback
+func (r *Router) controllerPath(controllerOPath string) string {
+	parts := strings.SplitN(controllerOPath, "/", 3)
+	if len(parts) < 3 || parts[0] != ModulePkg {
+		return rePath.Replace("/" + controllerOPath)
+	}
+
+	r.mountModule(parts[1])
+	return r.modules[parts[1]] + rePath.Replace("/"+parts[2])
+}
+
+// moduleIndex get path of default controller if path is prefix of module
+func (r *Router) moduleIndex(path string) (string, bool) {
+	path = strings.TrimRight(path, "/")
+	for _, prefix := range r.modules {
+		if prefix != "" && prefix == path {
+			return prefix + "/" + DefaultControllerPath, true
+		}
+	}
+
+	return "", false
+}
+
 // SetRules set rule list, format: `^/api/user/(\d+)$ => /api/user`
+// or declarative format: `/api/user/{id:int} => /api/user`
 func (r *Router) SetRules(rules []interface{}) {
 	for _, v := range rules {
 		parts := strings.Split(v.(string), "=>")
@@ -99,11 +331,33 @@ func (r *Router) SetRules(rules []interface{}) {
 func (r *Router) InitHandlers() {
 	r.webHandlers = make(map[string]*Handler)
 	r.cmdHandlers = make(map[string]*Handler)
+	r.webUrls = make(map[string]string)
+	r.overridden = nil
+	bindBuiltinCommands(App().Container())
 	webList := App().Container().PathList(ControllerWebPkg+"/", ControllerWebType)
 	cmdList := App().Container().PathList(ControllerCmdPkg+"/", ControllerCmdType)
+
+	// controllers of modules, eg. modules/feedback/controller/api/FeedbackController
+	for name, info := range App().Container().PathList(ModulePkg+"/", "") {
+		parts := strings.SplitN(name, "/", 4)
+		if len(parts) < 4 {
+			continue
+		}
+
+		r.mountModule(parts[1])
+		if parts[2] == ControllerWebPkg {
+			webList[name] = info
+		} else if parts[2] == ControllerCmdPkg {
+			cmdList[name] = info
+		}
+	}
+
 	r.SetHandlers(ControllerWebPkg, webList)
 	r.SetHandlers(ControllerCmdPkg, cmdList)
 
+	// all classes are bound before handlers are initialized
+	App().Container().ValidateInjects()
+
 }
 
 // SetHandlers Set route
@@ -114,7 +368,10 @@ func (r *Router) SetHandlers(cmdType string, list map[string]interface{}) {
 
 	for controllerOPath, info := range list {
 		actions, _ := info.(map[string]int)
-		controllerPath := rePath.Replace("/" + controllerOPath)
+		controllerPath := r.controllerPath(controllerOPath)
+		if r.web(cmdType) {
+			r.addVersion(controllerPath)
+		}
 
 		paths := strings.Split(controllerPath, "/")
 		oCname := paths[len(paths)-1:][0]
@@ -132,10 +389,17 @@ func (r *Router) SetHandlers(cmdType string, list map[string]interface{}) {
 			}
 		}
 
+		r.checkParams(cmdType, controllerOPath, actions)
+
 		for oAName, aNum := range actions {
+			var methods []string
 			aNames := make([]string, 0, 2)
-			aName := oAName
 			restFul, _ := restFulActions[oAName]
+			if restFul != 1 && r.web(cmdType) {
+				oAName, methods = r.actionMethods(controllerOPath, oAName)
+			}
+
+			aName := oAName
 			if restFul != 1 {
 				aName = r.firstToLower(oAName)
 			}
@@ -151,11 +415,11 @@ func (r *Router) SetHandlers(cmdType string, list map[string]interface{}) {
 			for _, cPath := range cNames {
 				for _, aPath := range aNames {
 					uri := baseUrl + cPath + "/" + aPath
-					r.setHandler(cmdType, uri, controllerOPath, baseUrl+cPath, oAName, aNum)
+					r.setHandler(cmdType, uri, controllerOPath, baseUrl+cPath, oAName, aNum, methods)
 
 					if aName == DefaultActionPath && r.web(cmdType) {
 						uri := baseUrl + cPath
-						r.setHandler(cmdType, uri, controllerOPath, baseUrl+cPath, oAName, aNum)
+						r.setHandler(cmdType, uri, controllerOPath, baseUrl+cPath, oAName, aNum, methods)
 					}
 				}
 
@@ -165,6 +429,20 @@ func (r *Router) SetHandlers(cmdType string, list map[string]interface{}) {
 	}
 }
 
+// checkParams check param types of actions of bound controller
+func (r *Router) checkParams(cmdType, cPath string, actions map[string]int) {
+	container := App().Container()
+	name := GetAlias(cPath)
+	if !container.Has(name) {
+		return
+	}
+
+	rt := reflect.PtrTo(container.GetType(name))
+	for _, aNum := range actions {
+		checkActionParams(rt, rt.Method(aNum), !r.web(cmdType))
+	}
+}
+
 func (r *Router) web(cmdType string) bool {
 	return cmdType == ControllerWebPkg
 }
@@ -173,11 +451,76 @@ func (r *Router) firstToLower(s string) string {
 	return strings.ToLower(s[0:1]) + s[1:]
 }
 
-func (r *Router) setHandler(cmdType, uri, cPath, cId, aName string, aNum int) {
+// actionMethods get action name and allowed http methods of action,
+// methods are declared by upper case name suffix, eg. ActionCreate_POST_PUT,
+// or by annotation of action if source of controller is found, eg. // @Method POST,PUT
+func (r *Router) actionMethods(cPath, aName string) (string, []string) {
+	if parts := strings.Split(aName, "_"); len(parts) > 1 {
+		valid := true
+		for _, m := range parts[1:] {
+			if _, ok := restFulActions[m]; !ok {
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			return parts[0], parts[1:]
+		}
+	}
+
+	container := App().Container()
+	if name := GetAlias(cPath); container.Has(name) {
+		return aName, annotatedMethods(container.GetType(name), ActionPrefix+aName, r.methodAnnotation)
+	}
+
+	return aName, nil
+}
+
+// annotatedMethods get methods by @Method annotation, nil is returned if
+// source of controller is not found, panic instead if required, panic
+// if annotation names invalid method.
+func annotatedMethods(rt reflect.Type, methodName string, required bool) []string {
+	p := NewParser()
+	if p.pkgRealPath(App().BasePath(), rt.PkgPath()) == "" {
+		if !required {
+			return nil
+		}
+
+		panic("Router: source of controller is required by @Method, " + rt.PkgPath() + "/" + rt.Name())
+	}
+
+	info := p.GetActionInfo(rt.PkgPath(), rt.Name(), methodName)
+	if info == nil {
+		if !required {
+			return nil
+		}
+
+		panic("Router: failed to read @Method of action, " + rt.PkgPath() + "/" + rt.Name() + "." + methodName)
+	}
+
+	return info.Methods
+}
+
+func (r *Router) setHandler(cmdType, uri, cPath, cId, aName string, aNum int, methods ...[]string) {
 	switch cmdType {
 	case ControllerWebPkg:
 		uri = strings.ToLower(uri)
-		r.webHandlers[uri] = &Handler{uri: uri, cPath: cPath, cId: cId, aName: aName, aId: aNum}
+		handler := &Handler{uri: uri, cPath: cPath, cId: cId, aName: aName, aId: aNum}
+		if len(methods) > 0 {
+			handler.methods = methods[0]
+		}
+
+		if old, ok := r.webHandlers[uri]; ok && (old.cPath != cPath || old.aName != aName) {
+			// the old one is not reachable by this uri
+			if r.overridden == nil {
+				r.overridden = make(map[string][]*Handler)
+			}
+			r.overridden[uri] = append(r.overridden[uri], old)
+		}
+
+		r.webHandlers[uri] = handler
+		r.setWebUrl(cId, aName, uri)
 	case ControllerCmdPkg:
 		r.cmdHandlers[uri] = &Handler{uri: uri, cPath: cPath, cId: cId, aName: aName, aId: aNum}
 	default:
@@ -185,16 +528,239 @@ func (r *Router) setHandler(cmdType, uri, cPath, cId, aName string, aNum int) {
 	}
 }
 
+// setWebUrl save preferred uri of action for reverse routing, uri with
+// less segments is preferred, then the kebab-case one.
+func (r *Router) setWebUrl(cId, aName, uri string) {
+	if r.webUrls == nil {
+		r.webUrls = make(map[string]string)
+	}
+
+	if _, ok := restFulActions[aName]; ok {
+		uri = strings.TrimSuffix(uri, "/"+strings.ToLower(aName))
+		if uri == "" {
+			uri = "/"
+		}
+	}
+
+	key := routeKey(cId, aName)
+	if old, ok := r.webUrls[key]; ok {
+		if n, m := strings.Count(uri, "/"), strings.Count(old, "/"); n > m ||
+			(n == m && strings.Count(uri, "-") <= strings.Count(old, "-")) {
+			return
+		}
+	}
+
+	r.webUrls[key] = uri
+}
+
+// routeKey key of action for reverse routing, case and dash insensitive
+func routeKey(cId, aName string) string {
+	if _, ok := restFulActions[aName]; !ok {
+		aName = strings.ToLower(strings.Replace(aName, "-", "", -1))
+	}
+
+	return strings.ToLower(strings.Replace(cId, "-", "", -1)) + "/" + aName
+}
+
 // AddRoute add one route, the captured group will be passed to
-// action method as function params
+// action method as function params, pattern is a regexp or declarative
+// path with named params like /user/{id:int}/photo/{pid}, builtin param
+// types are int, uint, float, alpha, word, uuid and path, other types
+// are used as regexp, eg. {code:[a-z]{2}}. named groups can be got by
+// ctx.PathParam, eg. {id} or (?P<id>\d+). declarative pattern whose
+// params are whole segments with builtin types is matched by radix
+// tree, others are matched by regexp, rules are matched in order of
+// adding, except that static segment takes precedence over param in tree.
 func (r *Router) AddRoute(pattern, route string) {
-	rePat := regexp.MustCompile(pattern)
-	rule := routeRule{rePat, pattern, route}
+	r.numRules++
+	expr := pattern
+	if isDeclarativeRoute(pattern) {
+		if tokens, ok := parseTreeRoute(pattern); ok {
+			r.addTreeRoute(pattern, route, tokens)
+			return
+		}
+
+		expr = compileRoute(pattern)
+	}
+
+	rePat := regexp.MustCompile(expr)
+	rule := routeRule{rePat, pattern, route, rePat.SubexpNames()[1:], r.numRules}
 	r.rules = append(r.rules, rule)
+	if isDeclarativeRoute(pattern) {
+		r.declRules = append(r.declRules, &rule)
+	}
+}
+
+// addTreeRoute add declarative rule to route tree
+func (r *Router) addTreeRoute(pattern, route string, tokens []routeToken) {
+	if r.tree == nil {
+		r.tree = &routeNode{}
+	}
+
+	names := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.param != nil {
+			names = append(names, token.param.name)
+		}
+	}
+
+	rule := &routeRule{pattern: pattern, route: route, names: names, order: r.numRules}
+	r.tree.add(tokens, rule)
+	r.declRules = append(r.declRules, rule)
+}
+
+// URL generate url of action, controllerId and actionId are the same as
+// ctx.ControllerId() and ctx.ActionId(), eg. URL("/api/user", "View").
+// params are filled into the first declarative rule routed to the action,
+// params can be positional values or a map[string]interface{} of named
+// params, named params not in rule are appended as query string.
+// the kebab-case uri is generated if no rule is matched, error is returned
+// if action is not found or params do not match the rules of action.
+func (r *Router) URL(controllerId, actionId string, params ...interface{}) (string, error) {
+	key := routeKey(controllerId, actionId)
+	uri, ok := r.webUrls[key]
+	if !ok {
+		return "", fmt.Errorf("Router: no action for url, %s/%s", controllerId, actionId)
+	}
+
+	var named map[string]interface{}
+	var positional []string
+	if len(params) == 1 {
+		switch v := params[0].(type) {
+		case map[string]interface{}:
+			named = v
+		case map[string]string:
+			named = make(map[string]interface{}, len(v))
+			for k, s := range v {
+				named[k] = s
+			}
+		}
+	}
+
+	if named == nil {
+		for _, v := range params {
+			positional = append(positional, util.ToString(v))
+		}
+	}
+
+	if len(params) > 0 {
+		for _, rule := range r.declRules {
+			h := r.webHandlers[strings.ToLower(rule.route)]
+			if h == nil || routeKey(h.cId, h.aName) != key {
+				continue
+			}
+
+			if u, ok := fillRoute(rule.pattern, positional, named); ok {
+				return u, nil
+			}
+		}
+
+		if named == nil {
+			return "", fmt.Errorf("Router: no rule for url with %d params, %s/%s", len(params), controllerId, actionId)
+		}
+	}
+
+	return appendQuery(uri, named, nil), nil
+}
+
+// MustURL generate url of action like URL, panic if failed
+func (r *Router) MustURL(controllerId, actionId string, params ...interface{}) string {
+	u, e := r.URL(controllerId, actionId, params...)
+	if e != nil {
+		panic(e.Error())
+	}
+
+	return u
+}
+
+// fillRoute fill params into declarative pattern, false is returned if
+// params do not match, named params not in pattern are appended as query.
+func fillRoute(pattern string, positional []string, named map[string]interface{}) (string, bool) {
+	locs := reRouteParam.FindAllStringSubmatchIndex(pattern, -1)
+	if named == nil && len(locs) != len(positional) {
+		return "", false
+	}
+
+	buf := &strings.Builder{}
+	used := make(map[string]bool, len(locs))
+	last := 0
+	for i, loc := range locs {
+		buf.WriteString(pattern[last:loc[0]])
+		last = loc[1]
+
+		name, typ := pattern[loc[2]:loc[3]], ""
+		if loc[4] != -1 {
+			typ = strings.TrimSpace(pattern[loc[4]:loc[5]])
+		}
+
+		value := ""
+		if named != nil {
+			v, ok := named[name]
+			if !ok {
+				return "", false
+			}
+
+			value, used[name] = util.ToString(v), true
+		} else {
+			value = positional[i]
+		}
+
+		if check, ok := routeParamCheckers[typ]; value == "" || (ok && !check(value)) {
+			return "", false
+		}
+
+		if typ == "path" {
+			segments := strings.Split(value, "/")
+			for j, seg := range segments {
+				segments[j] = url.PathEscape(seg)
+			}
+			value = strings.Join(segments, "/")
+		} else {
+			value = url.PathEscape(value)
+		}
+
+		buf.WriteString(value)
+	}
+
+	buf.WriteString(pattern[last:])
+	return appendQuery(buf.String(), named, used), true
+}
+
+// appendQuery append named params not used as query string
+func appendQuery(uri string, named map[string]interface{}, used map[string]bool) string {
+	query := url.Values{}
+	for k, v := range named {
+		if !used[k] {
+			query.Set(k, util.ToString(v))
+		}
+	}
+
+	if len(query) == 0 {
+		return uri
+	}
+
+	return uri + "?" + query.Encode()
 }
 
 // Resolve path to route and action params, then format route to CamelCase
 func (r *Router) Resolve(path, method string) (handler *Handler, params []string) {
+	handler, params, _ = r.resolve(path, method)
+	return
+}
+
+// resolve path to route, action params and names of params,
+// nil handler is returned if method is not allowed by handler
+func (r *Router) resolve(path, method string) (handler *Handler, params, names []string) {
+	handler, params, names = r.match(path, method)
+	if handler != nil && ModeWeb == App().mode && !handler.allow(method) {
+		return nil, nil, nil
+	}
+
+	return
+}
+
+// match path to route, action params and names of params
+func (r *Router) match(path, method string) (handler *Handler, params, names []string) {
 	// The first mapping
 	handler = r.Handler(path)
 	if handler != nil {
@@ -206,12 +772,16 @@ func (r *Router) Resolve(path, method string) (handler *Handler, params []string
 	if path == "/" {
 		path += DefaultControllerPath + "/" + DefaultActionPath
 	} else {
+		// root of module is mapped to default controller of module
+		if index, ok := r.moduleIndex(path); ok {
+			if handler = r.Handler(index); handler != nil {
+				return
+			}
+			path = index
+		}
+
 		restFulSuffix = "/" + method
 		path += restFulSuffix
-
-		//if r.modules != nil && util.SliceSearchString(r.modules, path) > 0 {
-		//	path += "/" + DefaultControllerPath + "/" + DefaultActionPath
-		//}
 	}
 
 	path = util.CleanPath(path)
@@ -221,26 +791,110 @@ func (r *Router) Resolve(path, method string) (handler *Handler, params []string
 		return
 	}
 
+	// serve HEAD by GET action
+	if method == http.MethodHead && restFulSuffix != "" {
+		if handler = r.Handler(strings.TrimSuffix(path, restFulSuffix) + "/" + http.MethodGet); handler != nil {
+			return
+		}
+	}
+
 	if restFulSuffix != "" {
 		path = strings.Replace(path, restFulSuffix, "", 1)
 	}
 
-	// Custom route
-	if len(r.rules) != 0 {
-		for _, rule := range r.rules {
-			matches := rule.rePat.FindStringSubmatch(path)
-			if len(matches) != 0 {
-				path = rule.route
-				params = matches[1:]
-				break
-			}
+	// Custom route in order of rules, regexp rules added before
+	// the matched rule of route tree take precedence over it.
+	var treeRule *routeRule
+	var treeValues []string
+	if r.tree != nil {
+		treeRule, treeValues = r.tree.match(path, nil)
+	}
+
+	for _, rule := range r.rules {
+		if treeRule != nil && rule.order > treeRule.order {
+			break
+		}
+
+		if matches := rule.rePat.FindStringSubmatch(path); len(matches) != 0 {
+			return r.Handler(rule.route), matches[1:], rule.names
 		}
 	}
+
+	if treeRule != nil {
+		return r.Handler(treeRule.route), treeValues, treeRule.names
+	}
+
 	handler = r.Handler(path)
 
 	return
 }
 
+// methods of RESTful action in order of Allow header
+var allowMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+
+// allowedMethods get allowed methods of request path,
+// virtual host and api version are considered
+func (r *Router) allowedMethods(ctx iface.IContext, path string) []string {
+	vh := r.host(ctx)
+	for _, vPath := range r.versionPaths(ctx, path) {
+		if hPath, ok := r.hostPath(vh, vPath); ok {
+			if allow := r.AllowedMethods(hPath); len(allow) > 0 {
+				return allow
+			}
+		}
+	}
+
+	return nil
+}
+
+// AllowedMethods get methods of RESTful actions of path or methods
+// of classic action restricted by @Method, HEAD is allowed if GET is
+// allowed, OPTIONS is always allowed if any method is allowed.
+// nil is returned if path is not restricted.
+func (r *Router) AllowedMethods(path string) []string {
+	if ModeWeb != App().mode || path == "/" {
+		return nil
+	}
+
+	var allow []string
+	path = util.CleanPath(path)
+	for _, method := range allowMethods {
+		if r.Handler(path+"/"+method) != nil ||
+			(method == http.MethodHead && r.Handler(path+"/"+http.MethodGet) != nil) {
+			allow = append(allow, method)
+		}
+	}
+
+	// methods of classic action
+	if len(allow) == 0 {
+		if handler, _, _ := r.match(path, http.MethodOptions); handler != nil && handler.methods != nil {
+			for _, method := range allowMethods {
+				if handler.allow(method) {
+					allow = append(allow, method)
+				}
+			}
+		}
+	}
+
+	if len(allow) > 0 && allow[len(allow)-1] != http.MethodOptions {
+		allow = append(allow, http.MethodOptions)
+	}
+
+	return allow
+}
+
+// pathParams map named params to value, unnamed params are omitted
+func pathParams(names, params []string) map[string]string {
+	m := make(map[string]string, len(names))
+	for i, name := range names {
+		if name != "" && i < len(params) {
+			m[name] = params[i]
+		}
+	}
+
+	return m
+}
+
 func (r *Router) Handler(path string) *Handler {
 
 	if ModeWeb == App().mode {
@@ -252,31 +906,63 @@ func (r *Router) Handler(path string) *Handler {
 		return nil
 	}
 
+	return r.cmdHandler(path)
+}
+
+// cmdHandler get handler of command regardless of running mode,
+// path is case insensitive, default action is used if omitted.
+func (r *Router) cmdHandler(path string) *Handler {
 	if handler, ok := r.cmdHandlers[path]; ok {
 		return handler
 	}
 
 	path = strings.ToLower(path)
+	index := strings.TrimSuffix(path, "/") + "/" + DefaultActionPath
+	var found *Handler
 	for kPath, handler := range r.cmdHandlers {
-		if strings.ToLower(kPath) == path {
+		switch strings.ToLower(kPath) {
+		case path:
 			return handler
+		case index:
+			found = handler
 		}
 	}
 
-	return nil
+	return found
 }
 
 func (r *Router) CmdHandlers() map[string]*Handler {
 	return r.cmdHandlers
 }
 
-// CreateController Create the controller and parameters
-func (r *Router) CreateController(path string, ctx iface.IContext) (reflect.Value, reflect.Value, []string) {
+// CreateController Create the controller and parameters, names of
+// params are returned too, name is empty if param is unnamed.
+func (r *Router) CreateController(path string, ctx iface.IContext) (reflect.Value, reflect.Value, []string, []string) {
 	container := App().Container()
 
-	handler, params := r.Resolve(path, ctx.Method())
+	var handler *Handler
+	var params, names []string
+	vh := r.host(ctx)
+	for _, vPath := range r.versionPaths(ctx, path) {
+		if hPath, ok := r.hostPath(vh, vPath); ok {
+			if handler, params, names = r.resolve(hPath, ctx.Method()); handler != nil {
+				break
+			}
+
+			// path exists in this version but method is not allowed,
+			// do not fall back to lower version, 405 is responded
+			if len(r.AllowedMethods(hPath)) > 0 {
+				break
+			}
+		}
+	}
+
 	if handler == nil {
-		return reflect.Value{}, reflect.Value{}, nil
+		return reflect.Value{}, reflect.Value{}, nil, nil
+	}
+
+	if len(names) > 0 {
+		ctx.SetPathParams(pathParams(names, params))
 	}
 
 	controllerName := handler.cPath
@@ -289,15 +975,32 @@ func (r *Router) CreateController(path string, ctx iface.IContext) (reflect.Valu
 
 	controller := container.Get(controllerName, ctx)
 	action := controller.Method(handler.aId)
-	return controller, action, params
+	return controller, action, params, names
 }
 
 func (r *Router) ErrorController(ctx iface.IContext, statuses ...int) iface.IController {
-	if r.httpStatus && len(statuses) > 0 && statuses[0] > 0 && ModeWeb == App().mode {
+	if r.httpStatus && len(statuses) > 0 && statuses[0] > 0 && ModeWeb == App().mode && ctx.Output() != nil {
 		ctx.Output().WriteHeader(statuses[0])
 	}
 	container := App().Container()
 	controllerName := GetAlias(r.errorController)
+	if vh := r.host(ctx); vh != nil && vh.errorController != "" {
+		controllerName = GetAlias(vh.errorController)
+	}
 	controller := container.Get(controllerName, ctx)
 	return controller.Interface().(iface.IController)
 }
+
+// ErrorResponse output error by error controller, problem details is
+// used if enabled and the error controller implements IProblemController
+func (r *Router) ErrorResponse(ctx iface.IContext, status int, message string, err error, statuses ...int) {
+	errCtl := r.ErrorController(ctx, statuses...)
+	if r.problemDetails {
+		if pc, ok := errCtl.(iface.IProblemController); ok {
+			pc.Problem(status, message, err)
+			return
+		}
+	}
+
+	errCtl.(iface.IErrorController).Error(status, message)
+}
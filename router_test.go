@@ -1,6 +1,11 @@
 package pgo2
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 
@@ -163,6 +168,68 @@ func TestRouter_Resolve(t *testing.T) {
 	})
 }
 
+func TestRouter_AddRoute_Declarative(t *testing.T) {
+	App(true)
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{"controller/IndexController": map[string]int{"Index": 0}})
+	router.SetRules([]interface{}{
+		"/user/{id:int}/photo/{pid} => /index/index",
+		"/lang/{code:[a-z]{2}}.json => /index/index",
+	})
+
+	if router.tree == nil || len(router.rules) != 1 {
+		t.Fatal("declarative rule should be added to route tree")
+	}
+
+	if router.rules[0].rePat.String() != `^/lang/(?P<code>[a-z]{2})\.json$` {
+		t.Fatal(router.rules[0].rePat.String())
+	}
+
+	h, p, names := router.resolve("/user/12/photo/abc", "GET")
+	if h == nil || len(p) != 2 || p[0] != "12" || p[1] != "abc" {
+		t.Fatal("resolve /user/12/photo/abc failed")
+	}
+
+	if m := pathParams(names, p); m["id"] != "12" || m["pid"] != "abc" {
+		t.Fatal("pathParams failed")
+	}
+
+	if h, _, _ := router.resolve("/user/abc/photo/abc", "GET"); h != nil {
+		t.Fatal("/user/abc/photo/abc should not match")
+	}
+
+	if h, p, _ := router.resolve("/lang/zh.json", "GET"); h == nil || p[0] != "zh" {
+		t.Fatal("resolve /lang/zh.json failed")
+	}
+
+	if h, _, _ := router.resolve("/lang/zhx.json", "GET"); h != nil {
+		t.Fatal("/lang/zhx.json should not match")
+	}
+}
+
+func TestRouter_RuleOrder(t *testing.T) {
+	App(true)
+	for _, c := range []struct {
+		rules  []interface{}
+		action string
+	}{
+		{[]interface{}{`^/user/(\d+)$ => /index/view`, "/user/{id:int} => /index/index"}, "/index/view"},
+		{[]interface{}{"/user/{id:int} => /index/index", `^/user/(\d+)$ => /index/view`}, "/index/index"},
+	} {
+		router := NewRouter(nil)
+		router.webHandlers = make(map[string]*Handler)
+		router.cmdHandlers = make(map[string]*Handler)
+		router.SetHandlers(ControllerWebPkg, map[string]interface{}{"controller/IndexController": map[string]int{"Index": 0, "View": 1}})
+		router.SetRules(c.rules)
+
+		if h, p, _ := router.resolve("/user/1", "GET"); h == nil || h != router.Handler(c.action) || p[0] != "1" {
+			t.Fatal("the first added rule should match, " + c.action)
+		}
+	}
+}
+
 func TestRouter_CreateController(t *testing.T) {
 	App(true)
 	var c *Container
@@ -184,7 +251,7 @@ func TestRouter_CreateController(t *testing.T) {
 
 	context := &Context{}
 
-	controller, action, _ := router.CreateController("/mock/index", context)
+	controller, action, _, _ := router.CreateController("/mock/index", context)
 	if !controller.IsValid() {
 		t.Fatal(`!controller.IsValid() `)
 	}
@@ -202,3 +269,335 @@ func TestRouter_CreateController(t *testing.T) {
 	}
 
 }
+
+func TestRouter_ProblemType(t *testing.T) {
+	router := NewRouter(map[string]interface{}{"problemTypeBase": "https://example.com/probs/"})
+	cases := map[string]string{
+		"":                              "about:blank",
+		"out-of-credit":                 "https://example.com/probs/out-of-credit",
+		"/out-of-credit":                "https://example.com/probs/out-of-credit",
+		"https://example.org/probs/foo": "https://example.org/probs/foo",
+	}
+
+	for typeUri, want := range cases {
+		if got := router.ProblemType(typeUri); got != want {
+			t.Fatalf("ProblemType(%q)=%q, want %q", typeUri, got, want)
+		}
+	}
+}
+
+func TestRouter_URL(t *testing.T) {
+	App(true)
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/api/UserInfoController": map[string]int{"Index": 0, "ViewPhoto": 1, "GET": 2},
+	})
+	router.SetRules([]interface{}{
+		"/user/{id:int}/photo/{pid} => /api/user-info/view-photo",
+		"/files/{file:path} => /api/user-info/view-photo",
+	})
+
+	cases := []struct {
+		cId, aId string
+		params   []interface{}
+		want     string
+	}{
+		{"/api/userInfo", "Index", nil, "/api/user-info"},
+		{"/api/user-info", "ViewPhoto", nil, "/api/user-info/view-photo"},
+		{"/api/userInfo", "GET", nil, "/api/user-info"},
+		{"/api/userInfo", "ViewPhoto", []interface{}{12, "a b"}, "/user/12/photo/a%20b"},
+		{"/api/userInfo", "ViewPhoto", []interface{}{"js/app.js"}, "/files/js/app.js"},
+		{"/api/userInfo", "ViewPhoto", []interface{}{map[string]interface{}{"id": 1, "pid": 2, "s": "x"}}, "/user/1/photo/2?s=x"},
+		{"/api/userInfo", "ViewPhoto", []interface{}{map[string]interface{}{"s": "x"}}, "/api/user-info/view-photo?s=x"},
+	}
+
+	for _, c := range cases {
+		if got := router.MustURL(c.cId, c.aId, c.params...); got != c.want {
+			t.Fatalf("URL(%s, %s, %v)=%s, want %s", c.cId, c.aId, c.params, got, c.want)
+		}
+	}
+
+	if _, e := router.URL("/api/userInfo", "ViewPhoto", "abc", 1); e == nil {
+		t.Fatal("URL should fail if no rule matched")
+	}
+
+	if _, e := router.URL("/api/none", "Index"); e == nil {
+		t.Fatal("URL should fail if action not found")
+	}
+}
+
+func TestRouter_AllowedMethods(t *testing.T) {
+	App(true)
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/api/UserController": map[string]int{"GET": 0, "POST": 1},
+	})
+
+	if allow := router.AllowedMethods("/api/user"); !reflect.DeepEqual(allow, []string{"GET", "HEAD", "POST", "OPTIONS"}) {
+		t.Fatal(allow)
+	}
+
+	if allow := router.AllowedMethods("/api/none"); allow != nil {
+		t.Fatal(allow)
+	}
+
+	if h, _ := router.Resolve("/api/user", "HEAD"); h == nil || h.aName != "GET" {
+		t.Fatal("HEAD should be served by GET")
+	}
+
+	if h, _ := router.Resolve("/api/user", "PUT"); h != nil {
+		t.Fatal("PUT should not be resolved")
+	}
+}
+
+func TestRouter_ActionMethods(t *testing.T) {
+	App(true)
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/UserController": map[string]int{"Create_POST_PUT": 0, "View_GET": 1, "Foo_bar": 2},
+	})
+
+	if h, _ := router.Resolve("/user/create", "POST"); h == nil || h.aName != "Create" {
+		t.Fatal("POST /user/create should be resolved")
+	}
+
+	if h, _ := router.Resolve("/user/create", "GET"); h != nil {
+		t.Fatal("GET /user/create should not be resolved")
+	}
+
+	if h, _ := router.Resolve("/user/view", "HEAD"); h == nil {
+		t.Fatal("HEAD /user/view should be resolved")
+	}
+
+	if h, _ := router.Resolve("/user/foo_bar", "GET"); h == nil {
+		t.Fatal("GET /user/foo_bar should be resolved")
+	}
+
+	if allow := router.AllowedMethods("/user/create"); !reflect.DeepEqual(allow, []string{"POST", "PUT", "OPTIONS"}) {
+		t.Fatal(allow)
+	}
+
+	// source of mockController is not found in base path
+	name := App().Container().Bind(&mockController{})
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{name: map[string]int{"Index": 0}})
+	router.SetMethodAnnotation(true)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("unreadable @Method should panic")
+		}
+	}()
+
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{name: map[string]int{"Index": 0}})
+}
+
+func TestRouter_MethodAnnotation(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "router")
+	defer os.RemoveAll(dir)
+
+	// source of mockController in base path
+	src := "package pgo2\n\n// @Method POST\nfunc (m *mockController) ActionIndex() {}\n"
+	os.MkdirAll(dir+"/pinguo/pgo2", 0755)
+	ioutil.WriteFile(dir+"/pinguo/pgo2/mock.go", []byte(src), 0644)
+
+	App(true).basePath = dir
+	name := App().Container().Bind(&mockController{})
+	pkgPath := reflect.TypeOf(mockController{}).PkgPath()
+	delete(controllerActionInfo, pkgPath)
+	defer delete(controllerActionInfo, pkgPath)
+
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{name: map[string]int{"Index": 0}})
+
+	if h, _ := router.Resolve("/github.com/pinguo/pgo2/mock/index", "GET"); h != nil {
+		t.Fatal("@Method should be honoured if source is found")
+	}
+
+	if h, _ := router.Resolve("/github.com/pinguo/pgo2/mock/index", "POST"); h == nil {
+		t.Fatal("POST /github.com/pinguo/pgo2/mock/index should be resolved")
+	}
+}
+
+func benchmarkRouter(regexp bool) *Router {
+	App(true)
+	router := NewRouter(nil)
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{"controller/IndexController": map[string]int{"Index": 0}})
+
+	for i := 0; i < 400; i++ {
+		pattern := fmt.Sprintf("/svc%d/user/{id:int}/photo/{pid}", i)
+		if regexp {
+			pattern = compileRoute(pattern)
+		}
+
+		router.AddRoute(pattern, "/index/index")
+	}
+
+	return router
+}
+
+func BenchmarkRouter_ResolveRegexp(b *testing.B) {
+	router := benchmarkRouter(true)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Resolve("/svc399/user/12/photo/abc", "GET")
+	}
+}
+
+func BenchmarkRouter_ResolveTree(b *testing.B) {
+	router := benchmarkRouter(false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Resolve("/svc399/user/12/photo/abc", "GET")
+	}
+}
+
+func TestRouter_Modules(t *testing.T) {
+	App(true)
+	router := NewRouter(map[string]interface{}{"modules": map[string]interface{}{"feedback": "/fb/"}})
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"modules/feedback/controller/IndexController":    map[string]int{"Index": 0},
+		"modules/feedback/controller/api/UserController": map[string]int{"View": 0},
+		"modules/photo/controller/PhotoController":       map[string]int{"GET": 0},
+	})
+
+	if h, _ := router.Resolve("/fb/api/user/view", "GET"); h == nil || h.cPath != "modules/feedback/controller/api/UserController" {
+		t.Fatal("/fb/api/user/view should be resolved")
+	}
+
+	if h, _ := router.Resolve("/fb", "GET"); h == nil || h.cPath != "modules/feedback/controller/IndexController" {
+		t.Fatal("/fb should be resolved to index of module")
+	}
+
+	if h, _ := router.Resolve("/photo/photo", "GET"); h == nil || h.aName != "GET" {
+		t.Fatal("/photo/photo should be resolved")
+	}
+
+	if name := router.Module("/fb/api/user"); name != "feedback" {
+		t.Fatal(`router.Module("/fb/api/user") != feedback`, name)
+	}
+
+	if name := router.Module("/photo/photo"); name != "photo" {
+		t.Fatal(`router.Module("/photo/photo") != photo`, name)
+	}
+
+	if name := router.Module("/fbx/user"); name != "" {
+		t.Fatal(`router.Module("/fbx/user") != ""`, name)
+	}
+}
+
+func TestRouter_Version(t *testing.T) {
+	App(true)
+	router := NewRouter(map[string]interface{}{"versionMedia": "vnd.app"})
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/UserController":    map[string]int{"View": 0},
+		"controller/v2/UserController": map[string]int{"View": 0},
+		"controller/v4/UserController": map[string]int{"View": 0},
+	})
+
+	if !reflect.DeepEqual(router.versions, []int{2, 4}) {
+		t.Fatal(`router.versions != [2 4]`, router.versions)
+	}
+
+	newCtx := func(path string, headers map[string]string) *Context {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+
+		ctx := &Context{}
+		ctx.SetInput(r)
+		return ctx
+	}
+
+	t.Run("path", func(t *testing.T) {
+		ctx := newCtx("/v3/user/view", nil)
+		paths := router.versionPaths(ctx, ctx.Path())
+		if !reflect.DeepEqual(paths, []string{"/v3/user/view", "/v2/user/view", "/user/view"}) {
+			t.Fatal(paths)
+		}
+
+		if v := router.Version(ctx); v != 3 {
+			t.Fatal(`router.Version(ctx) != 3`, v)
+		}
+	})
+
+	t.Run("header", func(t *testing.T) {
+		ctx := newCtx("/user/view", map[string]string{DefaultVersionHeader: "v5"})
+		paths := router.versionPaths(ctx, ctx.Path())
+		if !reflect.DeepEqual(paths, []string{"/v5/user/view", "/v4/user/view", "/v2/user/view", "/user/view"}) {
+			t.Fatal(paths)
+		}
+	})
+
+	t.Run("media", func(t *testing.T) {
+		ctx := newCtx("/user/view", map[string]string{"Accept": "application/vnd.app.v2+json"})
+		if v := router.Version(ctx); v != 2 {
+			t.Fatal(`router.Version(ctx) != 2`, v)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		ctx := newCtx("/user/view", nil)
+		paths := router.versionPaths(ctx, ctx.Path())
+		if !reflect.DeepEqual(paths, []string{"/user/view"}) {
+			t.Fatal(paths)
+		}
+	})
+
+	t.Run("noFallback", func(t *testing.T) {
+		router.SetVersionFallback(false)
+		defer router.SetVersionFallback(true)
+
+		ctx := newCtx("/v3/user/view", nil)
+		paths := router.versionPaths(ctx, ctx.Path())
+		if !reflect.DeepEqual(paths, []string{"/v3/user/view"}) {
+			t.Fatal(paths)
+		}
+	})
+
+	if h, _ := router.Resolve("/v2/user/view", http.MethodGet); h == nil || h.cPath != "controller/v2/UserController" {
+		t.Fatal("/v2/user/view should be resolved")
+	}
+
+	t.Run("methodDropped", func(t *testing.T) {
+		App().router = router
+		router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+			"controller/v2/OrderController": map[string]int{"GET": 0, "POST": 1},
+			"controller/v4/OrderController": map[string]int{"GET": 0},
+		})
+
+		ctx := newCtx("/v4/order", nil)
+		ctx.Input().Method = http.MethodPost
+		if rv, _, _, _ := router.CreateController(ctx.Path(), ctx); rv.IsValid() {
+			t.Fatal(`POST dropped in v4 should not fall back to v2`)
+		}
+
+		if allow := router.allowedMethods(ctx, ctx.Path()); !reflect.DeepEqual(allow, []string{"GET", "HEAD", "OPTIONS"}) {
+			t.Fatal(`allowed methods of v4 mismatch`, allow)
+		}
+	})
+}
+
+func TestRouter_SplitVersion(t *testing.T) {
+	router := NewRouter(map[string]interface{}{"modules": map[string]interface{}{"feedback": "/fb"}})
+	if prefix, v, rest := router.splitVersion("/fb/v2/user/view"); prefix != "/fb" || v != 2 || rest != "/user/view" {
+		t.Fatal(prefix, v, rest)
+	}
+
+	if prefix, v, rest := router.splitVersion("/vip/view"); prefix != "" || v != 0 || rest != "/vip/view" {
+		t.Fatal(prefix, v, rest)
+	}
+}
@@ -0,0 +1,277 @@
+package pgo2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pinguo/pgo2/iface"
+)
+
+// RouteInfo web route of action, uris are all the CamelCase and
+// kebab-case variants registered, conflicts are the uris also
+// registered by other actions, the action is not reachable by
+// the uri if it's overridden.
+type RouteInfo struct {
+	Uris         []string `json:"uris"`
+	Methods      []string `json:"methods"`
+	Controller   string   `json:"controller"`
+	ControllerId string   `json:"controllerId"`
+	Action       string   `json:"action"`
+	Rules        []string `json:"rules,omitempty"`
+	Plugins      []string `json:"plugins,omitempty"`
+	Conflicts    []string `json:"conflicts,omitempty"`
+	Overridden   bool     `json:"overridden,omitempty"`
+}
+
+// RuleInfo custom rule, shadowed are the uris matched by the rule
+// but resolved to actions directly, unknown means no action of route.
+type RuleInfo struct {
+	Pattern  string   `json:"pattern"`
+	Route    string   `json:"route"`
+	Shadowed []string `json:"shadowed,omitempty"`
+	Unknown  bool     `json:"unknown,omitempty"`
+}
+
+// RouteList web routes and custom rules
+type RouteList struct {
+	Routes []*RouteInfo `json:"routes"`
+	Rules  []*RuleInfo  `json:"rules,omitempty"`
+}
+
+// Routes list web routes and custom rules for introspection
+func (r *Router) Routes() *RouteList {
+	list := &RouteList{}
+	infos := make(map[string]*RouteInfo)
+	info := func(handler *Handler) *RouteInfo {
+		key := handler.cPath + "/" + handler.aName
+		if v, ok := infos[key]; ok {
+			return v
+		}
+
+		v := &RouteInfo{
+			Methods:      routeMethods(handler),
+			Controller:   handler.cPath,
+			ControllerId: handler.cId,
+			Action:       handler.aName,
+			Plugins:      r.routePlugins(handler.cId),
+		}
+		infos[key] = v
+		list.Routes = append(list.Routes, v)
+		return v
+	}
+
+	for _, uri := range sortedHandlerUris(r.webHandlers) {
+		handler := r.webHandlers[uri]
+		v := info(handler)
+		v.Uris = appendUnique(v.Uris, requestUri(handler, uri))
+		for _, old := range r.overridden[uri] {
+			v.Conflicts = appendUnique(v.Conflicts, uri+" of "+old.cPath+"/"+old.aName)
+		}
+	}
+
+	for _, uri := range sortedHandlerUris(r.overridden) {
+		for _, old := range r.overridden[uri] {
+			v := info(old)
+			v.Uris = appendUnique(v.Uris, requestUri(old, uri))
+			v.Conflicts = appendUnique(v.Conflicts, uri+" of "+r.webHandlers[uri].cPath+"/"+r.webHandlers[uri].aName)
+			if r.reachable(old) {
+				continue
+			}
+
+			v.Overridden = true
+		}
+	}
+
+	for _, rule := range r.allRules() {
+		ri := &RuleInfo{Pattern: rule.pattern, Route: rule.route}
+		handlers := r.ruleHandlers(rule.route)
+		ri.Unknown = len(handlers) == 0
+		for _, handler := range handlers {
+			v := info(handler)
+			v.Rules = appendUnique(v.Rules, rule.pattern)
+		}
+
+		rePat := rule.rePat
+		if rePat == nil {
+			rePat = regexp.MustCompile(compileRoute(rule.pattern))
+		}
+
+		for _, v := range list.Routes {
+			for _, uri := range v.Uris {
+				if rePat.MatchString(uri) {
+					ri.Shadowed = appendUnique(ri.Shadowed, uri)
+				}
+			}
+		}
+
+		sort.Strings(ri.Shadowed)
+		list.Rules = append(list.Rules, ri)
+	}
+
+	sort.Slice(list.Routes, func(i, j int) bool {
+		return list.Routes[i].Uris[0] < list.Routes[j].Uris[0]
+	})
+
+	return list
+}
+
+// reachable check whether handler is reachable by any uri
+func (r *Router) reachable(handler *Handler) bool {
+	for _, h := range r.webHandlers {
+		if h.cPath == handler.cPath && h.aName == handler.aName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allRules declarative rules in tree and regexp rules in order of matching
+func (r *Router) allRules() []*routeRule {
+	rules := make([]*routeRule, 0, len(r.declRules)+len(r.rules))
+	for _, rule := range r.declRules {
+		if rule.rePat == nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	for i := range r.rules {
+		rules = append(rules, &r.rules[i])
+	}
+
+	return rules
+}
+
+// routePlugins names of plugin chain of controller,
+// plugins of virtual host which prefix matches are used
+func (r *Router) routePlugins(cId string) []string {
+	if r.hosts != nil {
+		for _, vh := range r.hosts.all() {
+			if vh.plugins != nil && vh.prefix != "" && strings.HasPrefix(strings.ToLower(cId)+"/", strings.ToLower(vh.prefix)+"/") {
+				return vh.plugins
+			}
+		}
+	}
+
+	names := make([]string, 0, len(App().Server().plugins))
+	for _, p := range App().Server().plugins {
+		if name := pluginName(p); name != "Server" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// pluginName name of plugin type, eg. Gzip
+func pluginName(p iface.IPlugin) string {
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// routeMethods http methods of handler, * means any
+func routeMethods(handler *Handler) []string {
+	if _, ok := restFulActions[handler.aName]; ok {
+		return []string{handler.aName}
+	}
+
+	if handler.methods != nil {
+		return handler.methods
+	}
+
+	return []string{"*"}
+}
+
+// requestUri uri of request, method suffix of RESTful action is trimmed
+func requestUri(handler *Handler, uri string) string {
+	if _, ok := restFulActions[handler.aName]; ok {
+		if uri = strings.TrimSuffix(uri, "/"+strings.ToLower(handler.aName)); uri == "" {
+			uri = "/"
+		}
+	}
+
+	return uri
+}
+
+func sortedHandlerUris(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	uris := make([]string, 0, len(keys))
+	for _, k := range keys {
+		uris = append(uris, k.String())
+	}
+
+	sort.Strings(uris)
+	return uris
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
+
+// printRoutes print web routes, format is text or json
+func printRoutes(format string) {
+	list := App().Router().Routes()
+	if format == "json" {
+		data, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println("Web routes:")
+	for _, v := range list.Routes {
+		fmt.Printf("  %-12s %s\n", strings.Join(v.Methods, ","), strings.Join(v.Uris, " | "))
+		fmt.Printf("  %-12s => %s %s\n", "", v.Controller, v.Action)
+		if len(v.Rules) > 0 {
+			fmt.Printf("  %-12s rules: %s\n", "", strings.Join(v.Rules, ", "))
+		}
+
+		if len(v.Plugins) > 0 {
+			fmt.Printf("  %-12s plugins: %s\n", "", strings.Join(v.Plugins, ", "))
+		}
+
+		for _, c := range v.Conflicts {
+			fmt.Printf("  %-12s [conflict] %s\n", "", c)
+		}
+
+		if v.Overridden {
+			fmt.Printf("  %-12s [overridden] not reachable\n", "")
+		}
+	}
+
+	if len(list.Rules) > 0 {
+		fmt.Println("\nCustom rules:")
+	}
+
+	for _, v := range list.Rules {
+		fmt.Printf("  %s => %s\n", v.Pattern, v.Route)
+		if v.Unknown {
+			fmt.Println("    [unknown] no action of route")
+		}
+
+		for _, uri := range v.Shadowed {
+			fmt.Printf("    [shadowed] %s is resolved directly\n", uri)
+		}
+	}
+}
+
+// handleRoutes output routes as json on debug server
+func handleRoutes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	data, _ := json.Marshal(App().Router().Routes())
+	w.Write(data)
+}
@@ -0,0 +1,75 @@
+package pgo2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRouter_Routes(t *testing.T) {
+	App(true)
+	App().Server().plugins = nil
+	App().Server().AddPlugin(NewGzip())
+
+	router := NewRouter(map[string]interface{}{"hosts": []interface{}{
+		map[string]interface{}{"host": "admin.example.com", "prefix": "/admin", "plugins": []interface{}{"file"}},
+	}})
+	router.webHandlers = make(map[string]*Handler)
+	router.cmdHandlers = make(map[string]*Handler)
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/UserInfoController":    map[string]int{"View": 0, "Save_POST": 1},
+		"controller/api/PhotoController":   map[string]int{"GET": 0},
+		"controller/admin/IndexController": map[string]int{"Index": 0},
+	})
+	router.SetHandlers(ControllerWebPkg, map[string]interface{}{
+		"controller/userInfoController": map[string]int{"View": 0},
+	})
+	router.AddRoute("/user-info/{name}", "/userInfo/view")
+	router.AddRoute("^/foo/(\\d+)$", "/foo/bar")
+
+	list := router.Routes()
+	routes := make(map[string]*RouteInfo)
+	for _, v := range list.Routes {
+		routes[v.Controller+"/"+v.Action] = v
+	}
+
+	if v := routes["controller/UserInfoController/View"]; v == nil || !v.Overridden || len(v.Conflicts) == 0 {
+		t.Fatal(`UserInfoController/View should be overridden`, v)
+	}
+
+	v := routes["controller/userInfoController/View"]
+	if v == nil || !reflect.DeepEqual(v.Uris, []string{"/user-info/view", "/userinfo/view"}) || len(v.Conflicts) != 2 {
+		t.Fatal(`invalid userInfoController/View`, v)
+	}
+
+	if !reflect.DeepEqual(v.Methods, []string{"*"}) || !reflect.DeepEqual(v.Rules, []string{"/user-info/{name}"}) {
+		t.Fatal(`invalid methods or rules`, v.Methods, v.Rules)
+	}
+
+	if v := routes["controller/UserInfoController/Save"]; v == nil || !reflect.DeepEqual(v.Methods, []string{"POST"}) || v.Overridden {
+		t.Fatal(`invalid UserInfoController/Save`, v)
+	}
+
+	if v := routes["controller/api/PhotoController/GET"]; v == nil || !reflect.DeepEqual(v.Uris, []string{"/api/photo"}) {
+		t.Fatal(`invalid api/PhotoController/GET`, v)
+	}
+
+	if v := routes["controller/api/PhotoController/GET"]; !reflect.DeepEqual(v.Plugins, []string{"Gzip"}) {
+		t.Fatal(`plugins of /api/photo should be default`, v.Plugins)
+	}
+
+	if v := routes["controller/admin/IndexController/Index"]; !reflect.DeepEqual(v.Plugins, []string{"file"}) {
+		t.Fatal(`plugins of /admin should be of host`, v.Plugins)
+	}
+
+	if len(list.Rules) != 2 {
+		t.Fatal(`len(list.Rules) != 2`)
+	}
+
+	if r := list.Rules[0]; !reflect.DeepEqual(r.Shadowed, []string{"/user-info/save", "/user-info/view"}) || r.Unknown {
+		t.Fatal(`/user-info/{name} should be shadowed`, r)
+	}
+
+	if r := list.Rules[1]; !r.Unknown {
+		t.Fatal(`route of ^/foo/(\d+)$ should be unknown`, r)
+	}
+}
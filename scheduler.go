@@ -0,0 +1,524 @@
+package pgo2
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinguo/pgo2/core"
+	"github.com/pinguo/pgo2/cron"
+	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/util"
+)
+
+// overlap policies of scheduler job, the policy decides what
+// to do if the job is still running when it is due again.
+const (
+	OverlapSkip  = "skip"  // skip this run
+	OverlapQueue = "queue" // run after the running one finished
+	OverlapAllow = "allow" // run concurrently
+)
+
+const (
+	LockNone        = "none" // disable distributed lock of job
+	DefaultLockTtl  = 30 * time.Second
+	DefaultLockKeep = 5 * time.Second
+)
+
+// Scheduler the scheduler component, runs command actions on cron
+// schedules of config or @Cron annotations, configuration:
+// scheduler:
+//     enable: true
+//     timezone: "Asia/Shanghai"
+//     annotation: true
+//     lock: "redis"
+//     lockTtl: "30s"
+//     lockKeep: "5s"
+//     jobs:
+//         syncUser:
+//             cmd: "/user/sync"
+//             spec: "*/5 * * * *"
+//             overlap: "skip"
+//             jitter: "10s"
+//             timezone: "UTC"
+//             lock: "redis"
+// enable starts scheduler in web process, annotation loads
+// @Cron of command actions, eg. // @Cron 0 3 * * * overlap=queue,
+// source of commands is required by annotation, a warning is logged once if not found,
+// lock is component id of distributed lock which makes a job run on
+// only one replica, it's disabled by "none", lease of lock is kept for
+// lockKeep plus jitter after job finished to avoid rerun by replicas.
+func NewScheduler(config map[string]interface{}) *Scheduler {
+	scheduler := &Scheduler{
+		location:   time.Local,
+		annotation: true,
+		lockTtl:    DefaultLockTtl,
+		lockKeep:   DefaultLockKeep,
+		jobs:       make(map[string]*schedulerJob),
+	}
+
+	core.Configure(scheduler, config)
+
+	return scheduler
+}
+
+type Scheduler struct {
+	enable     bool           // start in web process
+	location   *time.Location // default timezone of jobs
+	annotation bool           // load @Cron of command actions
+	jobConf    map[string]interface{}
+	lockId     string        // component id of distributed lock
+	lockTtl    time.Duration // lease of distributed lock
+	lockKeep   time.Duration // lease kept after job finished
+
+	jobs   map[string]*schedulerJob
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	lock   sync.Mutex
+}
+
+type schedulerJob struct {
+	name     string
+	cmd      string
+	spec     string
+	overlap  string
+	jitter   time.Duration
+	location *time.Location
+
+	lock     string
+	locker   ComponentLock
+	schedule *cron.Schedule
+	handler  *Handler
+	sem      chan struct{}
+}
+
+// SetEnable set whether to start scheduler in web process
+func (s *Scheduler) SetEnable(v bool) {
+	s.enable = v
+}
+
+// SetTimezone set default timezone of jobs, eg. UTC, Local, Asia/Shanghai
+func (s *Scheduler) SetTimezone(v string) {
+	s.location = loadLocation(v)
+}
+
+// SetAnnotation set whether to load @Cron of command actions
+func (s *Scheduler) SetAnnotation(v bool) {
+	s.annotation = v
+}
+
+// SetJobs set jobs by name, jobs are created on start
+func (s *Scheduler) SetJobs(v map[string]interface{}) {
+	s.jobConf = v
+}
+
+// SetLock set component id of distributed lock, eg. redis
+func (s *Scheduler) SetLock(v string) {
+	s.lockId = v
+}
+
+// SetLockTtl set lease of distributed lock
+func (s *Scheduler) SetLockTtl(v string) {
+	if ttl, err := time.ParseDuration(v); err != nil {
+		panic(fmt.Sprintf("Scheduler: SetLockTtl failed, val:%s, err:%s", v, err.Error()))
+	} else {
+		s.lockTtl = ttl
+	}
+}
+
+// SetLockKeep set lease kept after job finished
+func (s *Scheduler) SetLockKeep(v string) {
+	if keep, err := time.ParseDuration(v); err != nil {
+		panic(fmt.Sprintf("Scheduler: SetLockKeep failed, val:%s, err:%s", v, err.Error()))
+	} else {
+		s.lockKeep = keep
+	}
+}
+
+// Enable whether to start scheduler in web process
+func (s *Scheduler) Enable() bool {
+	return s.enable
+}
+
+// Start create jobs and start scheduling, panic if any job is invalid
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	s.loadJobs()
+	// initialize log before jobs
+	App().Log()
+	GLogger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, job := range s.jobs {
+		GLogger().Info(fmt.Sprintf("scheduler job %s added, cmd:%s, spec:%s, overlap:%s", job.name, job.cmd, job.spec, job.overlap))
+
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop stop scheduling, context of running jobs is cancelled,
+// it returns after all running jobs finished.
+func (s *Scheduler) Stop() {
+	s.lock.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.lock.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	s.wg.Wait()
+	GLogger().Info("scheduler stopped")
+}
+
+// loadJobs create jobs of config and annotations
+func (s *Scheduler) loadJobs() {
+	s.jobs = make(map[string]*schedulerJob)
+	for name, v := range s.jobConf {
+		conf, ok := v.(map[string]interface{})
+		if !ok {
+			panic("Scheduler: invalid job config, " + name)
+		}
+
+		s.addJob(name, conf)
+	}
+
+	if s.annotation {
+		s.loadAnnotations()
+	}
+}
+
+// loadAnnotations create jobs of @Cron annotations, the job named
+// by uri of action is ignored if action is scheduled by config.
+func (s *Scheduler) loadAnnotations() {
+	scheduled := make(map[*Handler]bool)
+	for _, job := range s.jobs {
+		scheduled[job.handler] = true
+	}
+
+	missing := make([]string, 0)
+	for uri, handler := range App().Router().CmdHandlers() {
+		if scheduled[handler] || strings.HasPrefix(uri, BuiltinCmdPrefix) {
+			continue
+		}
+
+		annotation, found, err := cronAnnotation(handler)
+		if !found {
+			missing = append(missing, uri)
+			continue
+		} else if err != nil {
+			GLogger().Error(fmt.Sprintf("scheduler failed to load @Cron of %s, err:%v", uri, err))
+			continue
+		} else if annotation == "" {
+			continue
+		}
+
+		spec, conf := parseCronAnnotation(annotation)
+		conf["cmd"], conf["spec"] = uri, spec
+		s.addJob(uri, conf)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		GLogger().Warn(fmt.Sprintf("scheduler ignored @Cron of %d commands as source is not found, disable annotation if source is not deployed, commands:%s",
+			len(missing), strings.Join(missing, ",")))
+	}
+}
+
+// cronAnnotation get @Cron of command action, found is false if
+// source of command is not found, error is returned if source is
+// failed to parse.
+func cronAnnotation(handler *Handler) (annotation string, found bool, err interface{}) {
+	defer func() {
+		if v := recover(); v != nil {
+			annotation, found, err = "", true, v
+		}
+	}()
+
+	rt := App().Container().GetType(GetAlias(handler.cPath))
+	method, ok := reflect.PtrTo(rt).MethodByName(ActionPrefix + handler.aName)
+	if !ok {
+		return "", true, nil
+	}
+
+	p := NewParser()
+	if p.pkgRealPath(App().BasePath(), rt.PkgPath()) == "" {
+		return "", false, nil
+	}
+
+	if info := p.GetActionInfo(rt.PkgPath(), rt.Name(), method.Name); info != nil {
+		return info.Cron, true, nil
+	}
+
+	return "", true, nil
+}
+
+// parseCronAnnotation split annotation to spec and options,
+// eg. "*/5 * * * * overlap=skip jitter=10s"
+func parseCronAnnotation(annotation string) (string, map[string]interface{}) {
+	spec := make([]string, 0, 5)
+	conf := make(map[string]interface{})
+	for _, field := range strings.Fields(annotation) {
+		if pos := strings.Index(field, "="); pos > 0 {
+			conf[field[:pos]] = field[pos+1:]
+			continue
+		}
+
+		spec = append(spec, field)
+	}
+
+	return strings.Join(spec, " "), conf
+}
+
+// addJob create job by config, panic if config is invalid
+func (s *Scheduler) addJob(name string, conf map[string]interface{}) {
+	job := &schedulerJob{
+		name:     name,
+		cmd:      util.ToString(conf["cmd"]),
+		spec:     util.ToString(conf["spec"]),
+		overlap:  OverlapSkip,
+		location: s.location,
+		lock:     s.lockId,
+		sem:      make(chan struct{}, 1),
+	}
+
+	if job.handler = App().Router().cmdHandler(job.cmd); job.handler == nil {
+		panic("Scheduler: cmd not found of job " + name + ", cmd:" + job.cmd)
+	}
+
+	schedule, err := cron.Parse(job.spec)
+	if err != nil {
+		panic("Scheduler: invalid spec of job " + name + ", " + err.Error())
+	}
+	job.schedule = schedule
+
+	if v, ok := conf["overlap"]; ok {
+		switch job.overlap = strings.ToLower(util.ToString(v)); job.overlap {
+		case OverlapSkip, OverlapQueue, OverlapAllow:
+		default:
+			panic("Scheduler: invalid overlap of job " + name + ", " + job.overlap)
+		}
+	}
+
+	if v, ok := conf["jitter"]; ok {
+		if job.jitter, err = time.ParseDuration(util.ToString(v)); err != nil {
+			panic("Scheduler: invalid jitter of job " + name + ", " + err.Error())
+		}
+	}
+
+	if v, ok := conf["timezone"]; ok {
+		job.location = loadLocation(util.ToString(v))
+	}
+
+	if v, ok := conf["lock"]; ok {
+		job.lock = util.ToString(v)
+	}
+
+	if job.lock != "" && job.lock != LockNone {
+		if _, kind := componentKind(job.lock); kind != nil && kind.Lock != nil {
+			job.locker = kind.Lock
+		} else {
+			panic("Scheduler: lock is not supported of job " + name + ", lock:" + job.lock)
+		}
+	}
+
+	s.jobs[name] = job
+}
+
+// loop wait and dispatch job until ctx is done
+func (s *Scheduler) loop(ctx context.Context, job *schedulerJob) {
+	defer s.wg.Done()
+
+	var slot time.Time
+	for {
+		from := time.Now().In(job.location)
+		if from.Before(slot) {
+			from = slot
+		}
+
+		if slot = job.schedule.Next(from); slot.IsZero() {
+			GLogger().Warn("scheduler job " + job.name + " will never run, spec:" + job.spec)
+			return
+		}
+
+		delay := time.Until(slot)
+		if job.jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(job.jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.dispatch(ctx, job, slot)
+	}
+}
+
+// dispatch run job by overlap policy
+func (s *Scheduler) dispatch(ctx context.Context, job *schedulerJob, slot time.Time) {
+	switch job.overlap {
+	case OverlapSkip:
+		select {
+		case job.sem <- struct{}{}:
+		default:
+			GLogger().Warn("scheduler job " + job.name + " skipped, the last run is not finished")
+			return
+		}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		switch job.overlap {
+		case OverlapQueue:
+			select {
+			case job.sem <- struct{}{}:
+			case <-ctx.Done():
+				GLogger().Warn("scheduler job " + job.name + " dropped from queue, scheduler stopped")
+				return
+			}
+			defer func() { <-job.sem }()
+		case OverlapSkip:
+			defer func() { <-job.sem }()
+		}
+
+		s.run(ctx, job, slot)
+	}()
+}
+
+// run run job of scheduled slot and write history to log, job is skipped
+// if distributed lock is held by other replicas or slot has been run,
+// job is cancelled by context on lease loss, fencing token is set to user data.
+func (s *Scheduler) run(ctx context.Context, job *schedulerJob, slot time.Time) {
+	data := map[string]interface{}{SchedulerJobKey: job.name}
+	if job.locker != nil {
+		held, token, release, err := job.locker(ctx, job.lock, "scheduler:"+App().Name()+":"+job.name, slot, s.lockTtl)
+		if err != nil {
+			GLogger().Error(fmt.Sprintf("scheduler job %s lock failed, cmd:%s, err:%s", job.name, job.cmd, err.Error()))
+			return
+		}
+
+		if held == nil {
+			GLogger().Info(fmt.Sprintf("scheduler job %s skipped, locked or run by other replica", job.name))
+			return
+		}
+
+		defer release(s.lockKeep + job.jitter)
+		ctx, data[SchedulerTokenKey] = held, token
+	}
+
+	start := time.Now()
+	GLogger().Info(fmt.Sprintf("scheduler job %s start, cmd:%s", job.name, job.cmd))
+
+	failure := App().Server().runCmd(ctx, job.cmd, data)
+
+	cost := time.Since(start).Nanoseconds() / 1e6
+	if failure != nil {
+		GLogger().Error(fmt.Sprintf("scheduler job %s failed, cmd:%s, cost:%dms, err:%s", job.name, job.cmd, cost, util.ToString(failure)))
+		return
+	}
+
+	GLogger().Info(fmt.Sprintf("scheduler job %s done, cmd:%s, cost:%dms", job.name, job.cmd, cost))
+}
+
+// JobInfo info of scheduled job
+type JobInfo struct {
+	Name    string
+	Cmd     string
+	Spec    string
+	Overlap string
+	Jitter  string
+	Next    time.Time
+}
+
+// Jobs get info of jobs sorted by name, jobs are loaded if not started
+func (s *Scheduler) Jobs() []*JobInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cancel == nil {
+		s.loadJobs()
+	}
+
+	list := make([]*JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		list = append(list, &JobInfo{
+			Name:    job.name,
+			Cmd:     job.cmd,
+			Spec:    job.spec,
+			Overlap: job.overlap,
+			Jitter:  job.jitter.String(),
+			Next:    job.schedule.Next(time.Now().In(job.location)),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// loadLocation load timezone by name, panic if failed
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic("Scheduler: invalid timezone " + name + ", " + err.Error())
+	}
+
+	return location
+}
+
+// cmdPlugin the plugin to call command action of context path
+// regardless of running mode, it's used to run command in web process.
+type cmdPlugin struct {
+	server *Server
+}
+
+func (p *cmdPlugin) HandleRequest(ctx iface.IContext) {
+	handler := App().Router().cmdHandler(ctx.Path())
+	if handler == nil {
+		panic("cmd not found, " + ctx.Path())
+	}
+
+	ctx.SetControllerId(handler.cId)
+	ctx.SetActionId(handler.aName)
+
+	rv := App().Container().Get(GetAlias(handler.cPath), ctx)
+	p.server.callAction(ctx, rv, rv.Method(handler.aId), nil, nil)
+}
+
+// runCmd run command action with a new context, user data is
+// set to context, failure is returned if the action panics or returns error.
+func (s *Server) runCmd(goCtx context.Context, path string, data map[string]interface{}) interface{} {
+	ctx := &Context{debug: s.debug}
+	ctx.SetEnableAccessLog(s.enableAccessLog)
+	ctx.SetAccessLogFormat(s.accessLogFormat)
+	ctx.setPath(path)
+	ctx.SetGoContext(goCtx)
+	for k, v := range data {
+		ctx.SetUserData(k, v)
+	}
+
+	ctx.Process([]iface.IPlugin{&cmdPlugin{server: s}})
+
+	return ctx.failure
+}
@@ -0,0 +1,235 @@
+package pgo2
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinguo/pgo2/util"
+)
+
+type cronTestCommand struct {
+	Controller
+}
+
+var cronTest struct {
+	runs             chan string
+	lock             sync.Mutex
+	running, maxRuns int
+}
+
+func (c *cronTestCommand) ActionBlock() {
+	cronTest.runs <- c.Context().UserData(SchedulerJobKey, "").(string)
+	<-c.Context().GoContext().Done()
+}
+
+func (c *cronTestCommand) ActionCount() {
+	cronTest.lock.Lock()
+	cronTest.running++
+	if cronTest.running > cronTest.maxRuns {
+		cronTest.maxRuns = cronTest.running
+	}
+	cronTest.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	cronTest.runs <- c.Context().UserData(SchedulerJobKey, "").(string)
+
+	cronTest.lock.Lock()
+	cronTest.running--
+	cronTest.lock.Unlock()
+}
+
+func (c *cronTestCommand) ActionToken() {
+	cronTest.runs <- util.ToString(c.Context().UserData(SchedulerTokenKey, 0))
+}
+
+func (c *cronTestCommand) ActionFail() {
+	panic("cron test fail")
+}
+
+func newCronTestScheduler(overlap string) *Scheduler {
+	App(true)
+	container := App().Container()
+	container.bind(&cronTestCommand{}, "command/cronTestCommand")
+	App().Router().InitHandlers()
+
+	cronTest.runs = make(chan string, 10)
+	cronTest.maxRuns = 0
+
+	scheduler := NewScheduler(map[string]interface{}{
+		"annotation": false,
+		"timezone":   "UTC",
+		"jobs": map[string]interface{}{
+			"block": map[string]interface{}{"cmd": "/cronTest/block", "spec": "* * * * *", "overlap": overlap},
+			"count": map[string]interface{}{"cmd": "/cronTest/count", "spec": "@hourly", "overlap": overlap, "jitter": "1s"},
+		},
+	})
+	scheduler.loadJobs()
+	App().Log()
+
+	return scheduler
+}
+
+func TestScheduler_Skip(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapSkip)
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.dispatch(ctx, scheduler.jobs["block"], time.Now())
+	if name := <-cronTest.runs; name != "block" {
+		t.Fatal(`job name != block`, name)
+	}
+
+	scheduler.dispatch(ctx, scheduler.jobs["block"], time.Now())
+	cancel()
+	scheduler.wg.Wait()
+
+	if len(cronTest.runs) != 0 {
+		t.Fatal(`overlapped run should be skipped`)
+	}
+}
+
+func TestScheduler_Queue(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapQueue)
+	for i := 0; i < 3; i++ {
+		scheduler.dispatch(context.Background(), scheduler.jobs["count"], time.Now())
+	}
+	scheduler.wg.Wait()
+
+	if len(cronTest.runs) != 3 || cronTest.maxRuns != 1 {
+		t.Fatal(`queued runs should run one by one`, len(cronTest.runs), cronTest.maxRuns)
+	}
+}
+
+func TestScheduler_Allow(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapAllow)
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.dispatch(ctx, scheduler.jobs["block"], time.Now())
+	scheduler.dispatch(ctx, scheduler.jobs["block"], time.Now())
+	<-cronTest.runs
+	<-cronTest.runs
+	cancel()
+	scheduler.wg.Wait()
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapSkip)
+	scheduler.Start()
+	if len(scheduler.jobs) != 2 {
+		t.Fatal(`len(jobs) != 2`)
+	}
+
+	jobs := scheduler.Jobs()
+	if jobs[0].Name != "block" || jobs[1].Jitter != "1s" || jobs[1].Next.Minute() != 0 {
+		t.Fatal(`invalid jobs info`, jobs[0], jobs[1])
+	}
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal(`scheduler should stop`)
+	}
+}
+
+func TestServer_RunCmd(t *testing.T) {
+	newCronTestScheduler(OverlapSkip)
+	if failure := App().Server().runCmd(context.Background(), "/cronTest/fail", nil); failure != "cron test fail" {
+		t.Fatal(`failure != cron test fail`, failure)
+	}
+
+	if failure := App().Server().runCmd(context.Background(), "/cronTest/count", map[string]interface{}{SchedulerJobKey: "foo"}); failure != nil {
+		t.Fatal(`failure should be nil`, failure)
+	}
+
+	if name := <-cronTest.runs; name != "foo" {
+		t.Fatal(`job name != foo`, name)
+	}
+}
+
+func TestScheduler_AddJob(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapSkip)
+	for _, conf := range []map[string]interface{}{
+		{"cmd": "/cronTest/none", "spec": "* * * * *"},
+		{"cmd": "/cronTest/count", "spec": "* * *"},
+		{"cmd": "/cronTest/count", "spec": "* * * * *", "overlap": "foo"},
+		{"cmd": "/cronTest/count", "spec": "* * * * *", "timezone": "Foo/Bar"},
+		{"cmd": "/cronTest/count", "spec": "* * * * *", "lock": "foo"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal(`invalid job should panic`, conf)
+				}
+			}()
+
+			scheduler.addJob("foo", conf)
+		}()
+	}
+}
+
+func TestScheduler_Lock(t *testing.T) {
+	scheduler := newCronTestScheduler(OverlapSkip)
+
+	// the last run slot is recorded by lock
+	var last time.Time
+	kept := time.Duration(0)
+	RegisterComponentKind("cronLock", &ComponentKind{
+		Ping: func(id string) error { return nil },
+		Lock: func(ctx context.Context, id, name string, slot time.Time, ttl time.Duration) (context.Context, int64, func(time.Duration), error) {
+			if !slot.After(last) {
+				return nil, 0, nil, nil
+			}
+
+			last = slot
+			return ctx, 7, func(keep time.Duration) { kept = keep }, nil
+		},
+	})
+	defer delete(componentKinds, "cronLock")
+
+	slot := time.Now().Truncate(time.Hour)
+	scheduler.addJob("token", map[string]interface{}{"cmd": "/cronTest/token", "spec": "@hourly", "jitter": "1s", "lock": "cronLock"})
+	scheduler.run(context.Background(), scheduler.jobs["token"], slot)
+	scheduler.run(context.Background(), scheduler.jobs["token"], slot)
+
+	if len(cronTest.runs) != 1 {
+		t.Fatal(`job should be skipped if slot has been run`, len(cronTest.runs))
+	}
+
+	if token := <-cronTest.runs; token != "7" {
+		t.Fatal(`token != 7`, token)
+	}
+
+	if kept != DefaultLockKeep+time.Second {
+		t.Fatal(`lease should be kept for lockKeep plus jitter`, kept)
+	}
+
+	scheduler.addJob("none", map[string]interface{}{"cmd": "/cronTest/token", "spec": "@hourly", "lock": LockNone})
+	if scheduler.jobs["none"].locker != nil {
+		t.Fatal(`lock of job should be disabled`)
+	}
+}
+
+func TestParseCronAnnotation(t *testing.T) {
+	spec, conf := parseCronAnnotation("*/5 * * * * overlap=queue jitter=10s")
+	if spec != "*/5 * * * *" {
+		t.Fatal(`spec != */5 * * * *`, spec)
+	}
+
+	if !reflect.DeepEqual(conf, map[string]interface{}{"overlap": "queue", "jitter": "10s"}) {
+		t.Fatal(`invalid options`, conf)
+	}
+}
+
+func TestCronAnnotation(t *testing.T) {
+	newCronTestScheduler(OverlapSkip)
+	handler := App().Router().CmdHandlers()["/cronTest/block"]
+	if annotation, found, err := cronAnnotation(handler); annotation != "" || found || err != nil {
+		t.Fatal(`source should not be found`, annotation, err)
+	}
+}
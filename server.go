@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/pinguo/pgo2/core"
 	"github.com/pinguo/pgo2/iface"
+	"github.com/pinguo/pgo2/perror"
 	"github.com/pinguo/pgo2/util"
 )
 
@@ -54,6 +56,11 @@ type ServerConfig struct {
 //     maxPostBodySize: 1048576
 //     debug:true
 //     disableCheckListen: true
+//     daemonBackoff: "1s"
+//     daemonMaxBackoff: "1m"
+//     exitCodes:
+//         400: 2
+//         404: 3
 func NewServer(config map[string]interface{}) *Server {
 	server := &Server{
 		maxHeaderBytes:  DefaultHeaderBytes,
@@ -61,6 +68,11 @@ func NewServer(config map[string]interface{}) *Server {
 		writeTimeout:    DefaultTimeout,
 		statsInterval:   60 * time.Second,
 		enableAccessLog: true,
+
+		daemonBackoff:    DefaultDaemonBackoff,
+		daemonMaxBackoff: DefaultDaemonMaxBackoff,
+
+		exitCodes: map[int]int{http.StatusBadRequest: 2},
 	}
 
 	server.pool.New = func() interface{} {
@@ -94,7 +106,15 @@ type Server struct {
 	debug           bool            // debug=true not recover panic ,Output more stack information
 	accessLogFormat iface.IAccessLogFormat
 
+	hostChains map[*vhost][]iface.IPlugin // plugin chains of virtual hosts
+
 	disableCheckListen bool // Close the check listener port
+
+	daemonBackoff    time.Duration // initial delay to restart a panicked daemon worker
+	daemonMaxBackoff time.Duration // max delay to restart a panicked daemon worker
+
+	exitCodes map[int]int // exit codes of failed command by status
+	exitCode  int         // exit code of the last command
 }
 
 // SetHttpAddr set http addr, if both httpAddr and httpsAddr
@@ -175,22 +195,70 @@ func (s *Server) SetDebug(v bool) {
 	s.debug = v
 }
 
-// SetPlugins set plugin by names
+// SetPlugins set plugin by names, plugins are created
+// when server starts, before plugins added by AddPlugin.
 func (s *Server) SetPlugins(v []interface{}) {
 	for _, vv := range v {
-		name := vv.(string)
-		switch name {
-		case "gzip":
-			s.AddPlugin(NewGzip())
-		case "file":
-			s.AddPlugin(NewFile(nil))
-		default:
-			panic("For the defined plug-in:" + name)
+		s.pluginNames = append(s.pluginNames, vv.(string))
+	}
+}
+
+// newPlugin create builtin plugin by name
+func (s *Server) newPlugin(name string) iface.IPlugin {
+	switch name {
+	case "gzip":
+		return NewGzip()
+	case "file":
+		return NewFile(nil)
+	case "etag":
+		return NewETag(nil)
+	case "cache":
+		return NewCache(nil)
+	default:
+		panic("For the defined plug-in:" + name)
+	}
+}
+
+// initHostPlugins create plugin chains of virtual hosts
+func (s *Server) initHostPlugins() {
+	router := App().Router()
+	if router.hosts == nil {
+		return
+	}
+
+	s.hostChains = make(map[*vhost][]iface.IPlugin)
+	for _, vh := range router.hosts.all() {
+		if vh.plugins == nil {
+			continue
+		}
+
+		plugins := make([]iface.IPlugin, 0, len(vh.plugins)+1)
+		for _, name := range vh.plugins {
+			plugins = append(plugins, s.newPlugin(name))
+		}
+
+		if plugins = append(plugins, s); len(plugins) > MaxPlugins {
+			panic("Server: too many plugins of host " + vh.host)
 		}
 
+		s.hostChains[vh] = plugins
 	}
 }
 
+// hostPlugins get plugin chain of virtual host, nil means the default chain
+func (s *Server) hostPlugins(r *http.Request) []iface.IPlugin {
+	router := App().Router()
+	if router.hosts == nil {
+		return nil
+	}
+
+	if vh := router.hosts.match(r.Host); vh != nil {
+		return s.hostChains[vh]
+	}
+
+	return nil
+}
+
 // AddPlugins add plugin
 func (s *Server) AddPlugin(v iface.IPlugin) {
 	s.plugins = append(s.plugins, v)
@@ -262,6 +330,24 @@ func (s *Server) Serve() {
 		return
 	}
 
+	// list web routes
+	if App().HasArg("routes") {
+		printRoutes(App().Arg("routes"))
+		return
+	}
+
+	// write OpenAPI document
+	if App().HasArg("openapi") {
+		writeOpenAPI(App().Arg("openapi"))
+		return
+	}
+
+	// write shell completion script
+	if App().HasArg("completion") {
+		writeCompletion(App().Arg("completion"))
+		return
+	}
+
 	// process http request
 	if s.httpAddr == "" && s.httpsAddr == "" {
 		s.httpAddr = DefaultHttpAddr
@@ -273,16 +359,39 @@ func (s *Server) Serve() {
 	s.handleDebug(&wg)
 	s.handleSignal(&wg)
 	s.handleStats(&wg)
+	s.handleScheduler()
 	wg.Wait()
+	// wait running jobs before StopBefore hooks
+	App().Scheduler().Stop()
 }
 
-// ServeCMD serve command request
+// ServeCMD serve command request, exit code is set if
+// the action panics or returns error, see ExitCode.
 func (s *Server) ServeCMD() {
+	if output := App().Arg("output"); output != "" && output != OutputJson {
+		panic("Server: invalid output, " + output)
+	}
+
+	if n := App().Arg("daemon"); n != "" {
+		workers, err := strconv.Atoi(n)
+		if err != nil || workers <= 0 {
+			panic("Server: invalid daemon workers, " + n)
+		}
+
+		s.ServeDaemon(workers)
+		return
+	}
+
 	ctx := Context{debug: s.debug}
 	ctx.SetEnableAccessLog(s.enableAccessLog)
 	ctx.SetAccessLogFormat(s.accessLogFormat)
 	// only apply the last plugin for command
 	ctx.Process(s.plugins[len(s.plugins)-1:])
+
+	s.exitCode = s.exitCodeOf(ctx.failure)
+	if outputJson() {
+		s.writeResult(&ctx)
+	}
 }
 
 // ServeHTTP serve http request
@@ -294,11 +403,21 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// increase request num
 	atomic.AddUint64(&s.numReq, 1)
 
+	// discard body of HEAD request, plugins see the same body as GET
+	if r.Method == http.MethodHead {
+		w = &headWrite{w}
+	}
+
 	ctx := s.pool.Get().(iface.IContext)
 
+	plugins := s.hostPlugins(r)
+	if plugins == nil {
+		plugins = s.plugins
+	}
+
 	ctx.HttpRW(s.debug, s.enableAccessLog, r, w)
 	ctx.SetAccessLogFormat(s.accessLogFormat)
-	ctx.Process(s.plugins)
+	ctx.Process(plugins)
 	s.pool.Put(ctx)
 }
 
@@ -309,22 +428,35 @@ func (s *Server) HandleRequest(ctx iface.IContext) {
 	path := ctx.Path()
 
 	// get new controller bind to this route
-	rv, action, params := App().Router().CreateController(path, ctx)
+	rv, action, params, names := App().Router().CreateController(path, ctx)
 	if !rv.IsValid() {
 		if s.help(rv, action, "") {
 			return
 		}
 
+		status, message := http.StatusNotFound, "route not found"
+		if allow := App().Router().allowedMethods(ctx, path); len(allow) > 0 {
+			ctx.SetHeader("Allow", strings.Join(allow, ", "))
+			if ctx.Method() == http.MethodOptions {
+				ctx.End(http.StatusNoContent, nil)
+				return
+			}
+
+			status, message = http.StatusMethodNotAllowed, "method not allowed"
+		}
+
+		setFailure(ctx, perror.NewWarn(status, message))
+
 		func() {
 			defer func() {
 				if err := recover(); err != nil {
-					ctx.End(http.StatusNotFound, []byte("route not found"))
+					ctx.End(status, []byte(message))
 					ctx.Error("%s, trace[%s]", util.ToString(err), util.PanicTrace(TraceMaxDepth, false, s.debug))
 				}
 
 			}()
 
-			App().Router().ErrorController(ctx, http.StatusNotFound).(iface.IErrorController).Error(http.StatusNotFound, "route not found")
+			App().Router().ErrorResponse(ctx, status, message, nil, status)
 		}()
 
 		return
@@ -334,25 +466,18 @@ func (s *Server) HandleRequest(ctx iface.IContext) {
 		return
 	}
 
+	s.callAction(ctx, rv, action, params, names)
+}
+
+// callAction call action of controller with hooks,
+// panic of action is handled by controller.
+func (s *Server) callAction(ctx iface.IContext, rv, action reflect.Value, params, names []string) {
 	actionId := ctx.ActionId()
 	controller := rv.Interface().(iface.IController)
 
-	// fill empty string for missing param
-	numIn := action.Type().NumIn()
-	if len(params) < numIn {
-		fill := make([]string, numIn-len(params))
-		params = append(params, fill...)
-	}
-
-	// prepare params for action call
-	callParams := make([]reflect.Value, 0)
-	for _, param := range params {
-		callParams = append(callParams, reflect.ValueOf(param))
-	}
-
 	defer func() {
 		if v := recover(); v != nil {
-
+			setFailure(ctx, v)
 			controller.HandlePanic(v, s.debug)
 		}
 
@@ -362,10 +487,22 @@ func (s *Server) HandleRequest(ctx iface.IContext) {
 
 	// before action hook
 	controller.BeforeAction(actionId)
+	// prepare params for action call, args of command are parsed to struct
+	var callParams []reflect.Value
+	if ctx.Input() == nil {
+		callParams = commandParams(rv, action, actionId, params, names)
+	} else {
+		callParams = actionParams(action, params, names)
+	}
 	// call action method
 	res := action.Call(callParams)
 	if len(res) > 0 {
-		controller.Response(s.parseActionResult(res))
+		v, err := s.parseActionResult(res)
+		if err != nil {
+			setFailure(ctx, err)
+		}
+
+		controller.Response(v, err)
 	}
 }
 
@@ -456,6 +593,15 @@ func (s *Server) handleDebug(wg *sync.WaitGroup) {
 		w.Write(data)
 	})
 
+	http.HandleFunc("/routes", handleRoutes)
+
+	http.HandleFunc("/log/level", handleLogLevel)
+
+	http.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(App().Router().OpenAPI().Json())
+	})
+
 	svr := s.newHttpServer(s.debugAddr)
 	svr.Handler = nil // use default handler
 	s.servers = append(s.servers, svr)
@@ -517,20 +663,28 @@ func (s *Server) checkListen(addr string) {
 }
 
 func (s *Server) handleSignal(wg *sync.WaitGroup) {
-	sig := make(chan os.Signal)
+	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		<-sig // wait signal
 		for _, svr := range s.servers {
 			GLogger().Info("stop running " + svr.Addr)
-			ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			svr.Shutdown(ctx)
+			cancel()
 			wg.Done()
 		}
 	}()
 }
 
+// handleScheduler start scheduler in web process if enabled
+func (s *Server) handleScheduler() {
+	if scheduler := App().Scheduler(); scheduler.Enable() {
+		scheduler.Start()
+	}
+}
+
 func (s *Server) handleStats(wg *sync.WaitGroup) {
 	timer := time.Tick(s.statsInterval)
 
@@ -553,7 +707,17 @@ func (s *Server) newHttpServer(addr string) *http.Server {
 	}
 }
 
+// addServerPlugin create plugins when app is initialized, plugins
+// with invalid config panic here instead of at the first request.
 func (s *Server) addServerPlugin() {
+	plugins := make([]iface.IPlugin, 0, len(s.pluginNames)+len(s.plugins)+1)
+	for _, name := range s.pluginNames {
+		plugins = append(plugins, s.newPlugin(name))
+	}
+
+	s.plugins = append(plugins, s.plugins...)
+	s.initHostPlugins()
+
 	// server is the last plugin
 	s.AddPlugin(s)
 
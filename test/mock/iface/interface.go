@@ -5,6 +5,7 @@
 package mock_iface
 
 import (
+	context "context"
 	template "html/template"
 	io "io"
 	http "net/http"
@@ -352,6 +353,41 @@ func (mr *MockIErrorControllerMockRecorder) Error(status, message interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockIErrorController)(nil).Error), status, message)
 }
 
+// MockIProblemController is a mock of IProblemController interface.
+type MockIProblemController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIProblemControllerMockRecorder
+}
+
+// MockIProblemControllerMockRecorder is the mock recorder for MockIProblemController.
+type MockIProblemControllerMockRecorder struct {
+	mock *MockIProblemController
+}
+
+// NewMockIProblemController creates a new mock instance.
+func NewMockIProblemController(ctrl *gomock.Controller) *MockIProblemController {
+	mock := &MockIProblemController{ctrl: ctrl}
+	mock.recorder = &MockIProblemControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIProblemController) EXPECT() *MockIProblemControllerMockRecorder {
+	return m.recorder
+}
+
+// Problem mocks base method.
+func (m *MockIProblemController) Problem(status int, message string, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Problem", status, message, err)
+}
+
+// Problem indicates an expected call of Problem.
+func (mr *MockIProblemControllerMockRecorder) Problem(status, message, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Problem", reflect.TypeOf((*MockIProblemController)(nil).Problem), status, message, err)
+}
+
 // MockIPlugin is a mock of IPlugin interface.
 type MockIPlugin struct {
 	ctrl     *gomock.Controller
@@ -1067,6 +1103,60 @@ func (mr *MockIContextMockRecorder) ParamAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParamAll", reflect.TypeOf((*MockIContext)(nil).ParamAll))
 }
 
+// SetPathParams mocks base method.
+func (m *MockIContext) SetPathParams(params map[string]string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPathParams", params)
+}
+
+// SetPathParams indicates an expected call of SetPathParams.
+func (mr *MockIContextMockRecorder) SetPathParams(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPathParams", reflect.TypeOf((*MockIContext)(nil).SetPathParams), params)
+}
+
+// PathParam mocks base method.
+func (m *MockIContext) PathParam(name, dft string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PathParam", name, dft)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// PathParam indicates an expected call of PathParam.
+func (mr *MockIContextMockRecorder) PathParam(name, dft interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathParam", reflect.TypeOf((*MockIContext)(nil).PathParam), name, dft)
+}
+
+// PathParamAll mocks base method.
+func (m *MockIContext) PathParamAll() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PathParamAll")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// PathParamAll indicates an expected call of PathParamAll.
+func (mr *MockIContextMockRecorder) PathParamAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathParamAll", reflect.TypeOf((*MockIContext)(nil).PathParamAll))
+}
+
+// GoContext mocks base method.
+func (m *MockIContext) GoContext() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GoContext")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// GoContext indicates an expected call of GoContext.
+func (mr *MockIContextMockRecorder) GoContext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoContext", reflect.TypeOf((*MockIContext)(nil).GoContext))
+}
+
 // ParamMap mocks base method.
 func (m *MockIContext) ParamMap(name string) map[string]string {
 	m.ctrl.T.Helper()
@@ -1502,3 +1592,54 @@ func (mr *MockIAccessLogFormatMockRecorder) Format(arg0 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Format", reflect.TypeOf((*MockIAccessLogFormat)(nil).Format), arg0)
 }
+
+// MockICacheStore is a mock of ICacheStore interface.
+type MockICacheStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockICacheStoreMockRecorder
+}
+
+// MockICacheStoreMockRecorder is the mock recorder for MockICacheStore.
+type MockICacheStoreMockRecorder struct {
+	mock *MockICacheStore
+}
+
+// NewMockICacheStore creates a new mock instance.
+func NewMockICacheStore(ctrl *gomock.Controller) *MockICacheStore {
+	mock := &MockICacheStore{ctrl: ctrl}
+	mock.recorder = &MockICacheStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICacheStore) EXPECT() *MockICacheStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockICacheStore) Get(key string) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockICacheStoreMockRecorder) Get(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockICacheStore)(nil).Get), key)
+}
+
+// Set mocks base method.
+func (m *MockICacheStore) Set(key string, data []byte, expire time.Duration) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", key, data, expire)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockICacheStoreMockRecorder) Set(key, data, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockICacheStore)(nil).Set), key, data, expire)
+}
@@ -6,6 +6,8 @@ package mock_render
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	io "io"
+	http "net/http"
 	reflect "reflect"
 )
 
@@ -85,3 +87,108 @@ func (mr *MockRenderMockRecorder) SetHttpCode(code interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHttpCode", reflect.TypeOf((*MockRender)(nil).SetHttpCode), code)
 }
+
+// MockStreamRender is a mock of StreamRender interface
+type MockStreamRender struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamRenderMockRecorder
+}
+
+// MockStreamRenderMockRecorder is the mock recorder for MockStreamRender
+type MockStreamRenderMockRecorder struct {
+	mock *MockStreamRender
+}
+
+// NewMockStreamRender creates a new mock instance
+func NewMockStreamRender(ctrl *gomock.Controller) *MockStreamRender {
+	mock := &MockStreamRender{ctrl: ctrl}
+	mock.recorder = &MockStreamRenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStreamRender) EXPECT() *MockStreamRenderMockRecorder {
+	return m.recorder
+}
+
+// Content mocks base method
+func (m *MockStreamRender) Content() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Content")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// Content indicates an expected call of Content
+func (mr *MockStreamRenderMockRecorder) Content() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Content", reflect.TypeOf((*MockStreamRender)(nil).Content))
+}
+
+// HttpCode mocks base method
+func (m *MockStreamRender) HttpCode() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HttpCode")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// HttpCode indicates an expected call of HttpCode
+func (mr *MockStreamRenderMockRecorder) HttpCode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HttpCode", reflect.TypeOf((*MockStreamRender)(nil).HttpCode))
+}
+
+// ContentType mocks base method
+func (m *MockStreamRender) ContentType() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContentType")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ContentType indicates an expected call of ContentType
+func (mr *MockStreamRenderMockRecorder) ContentType() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContentType", reflect.TypeOf((*MockStreamRender)(nil).ContentType))
+}
+
+// SetHttpCode mocks base method
+func (m *MockStreamRender) SetHttpCode(code int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetHttpCode", code)
+}
+
+// SetHttpCode indicates an expected call of SetHttpCode
+func (mr *MockStreamRenderMockRecorder) SetHttpCode(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHttpCode", reflect.TypeOf((*MockStreamRender)(nil).SetHttpCode), code)
+}
+
+// Reader mocks base method
+func (m *MockStreamRender) Reader() io.Reader {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reader")
+	ret0, _ := ret[0].(io.Reader)
+	return ret0
+}
+
+// Reader indicates an expected call of Reader
+func (mr *MockStreamRenderMockRecorder) Reader() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reader", reflect.TypeOf((*MockStreamRender)(nil).Reader))
+}
+
+// Serve mocks base method
+func (m *MockStreamRender) Serve(w http.ResponseWriter, r *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Serve", w, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Serve indicates an expected call of Serve
+func (mr *MockStreamRenderMockRecorder) Serve(w interface{}, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Serve", reflect.TypeOf((*MockStreamRender)(nil).Serve), w, r)
+}
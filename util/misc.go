@@ -166,6 +166,35 @@ func PanicTrace(maxDepth int, multiLine, debug bool) string {
 	return strings.Join(sources, ",")
 }
 
+// StackTrace format call stack captured by runtime.Callers in the same
+// format as PanicTrace, system files are skipped.
+func StackTrace(pcs []uintptr, maxDepth int, multiLine bool) string {
+	sources := make([]string, 0, maxDepth)
+	frames := runtime.CallersFrames(pcs)
+	goRoot := runtime.GOROOT()
+
+	for len(sources) < maxDepth {
+		frame, more := frames.Next()
+		if frame.File != "" && (goRoot == "" || !strings.HasPrefix(frame.File, goRoot)) {
+			file := frame.File
+			if pos := strings.Index(file, "/pkg/"); pos != -1 {
+				file = file[pos+5:]
+			}
+
+			sources = append(sources, file+":"+strconv.Itoa(frame.Line))
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	if multiLine {
+		return strings.Join(sources, "\n")
+	}
+	return strings.Join(sources, ",")
+}
+
 // FormatVersion format version to have minimum depth,
 // eg. FormatVersion("v10...2....2.1-alpha", 5) == "v10.2.2.1.0-alpha"
 func FormatVersion(ver string, minDepth int) string {
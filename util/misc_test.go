@@ -3,6 +3,8 @@ package util
 import (
 	"bytes"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -94,3 +96,13 @@ func TestVersionCompare(t *testing.T) {
 		}
 	})
 }
+
+func TestStackTrace(t *testing.T) {
+	pcs := make([]uintptr, 10)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	trace := StackTrace(pcs, 1, false)
+	if !strings.Contains(trace, "misc_test.go:") || strings.Contains(trace, ",") {
+		t.Fatal(trace)
+	}
+}
@@ -1,11 +1,5 @@
 package validate
 
-import (
-	"net/http"
-
-	"github.com/pinguo/pgo2/perror"
-)
-
 // Bool validator for bool value
 type Bool struct {
 	Name   string
@@ -15,7 +9,7 @@ type Bool struct {
 
 func (b *Bool) Must(v bool) *Bool {
 	if !b.UseDft && b.Value != v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s must be %v", b.Name, v))
+		panic(paramError(b.Name, "%s must be %v", v))
 	}
 	return b
 }
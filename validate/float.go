@@ -1,11 +1,5 @@
 package validate
 
-import (
-	"net/http"
-
-	"github.com/pinguo/pgo2/perror"
-)
-
 // Float validator for float value
 type Float struct {
 	Name   string
@@ -15,14 +9,14 @@ type Float struct {
 
 func (f *Float) Min(v float64) *Float {
 	if !f.UseDft && f.Value < v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too small", f.Name))
+		panic(paramError(f.Name, "%s is too small"))
 	}
 	return f
 }
 
 func (f *Float) Max(v float64) *Float {
 	if !f.UseDft && f.Value > v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too large", f.Name))
+		panic(paramError(f.Name, "%s is too large"))
 	}
 	return f
 }
@@ -1,11 +1,5 @@
 package validate
 
-import (
-	"net/http"
-
-	"github.com/pinguo/pgo2/perror"
-)
-
 // Int validator for int value
 type Int struct {
 	Name   string
@@ -15,14 +9,14 @@ type Int struct {
 
 func (i *Int) Min(v int) *Int {
 	if !i.UseDft && i.Value < v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too small", i.Name))
+		panic(paramError(i.Name, "%s is too small"))
 	}
 	return i
 }
 
 func (i *Int) Max(v int) *Int {
 	if !i.UseDft && i.Value > v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too large", i.Name))
+		panic(paramError(i.Name, "%s is too large"))
 	}
 	return i
 }
@@ -37,7 +31,7 @@ func (i *Int) Enum(enums ...int) *Int {
 	}
 
 	if !i.UseDft && !found {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", i.Name))
+		panic(paramError(i.Name, "%s is invalid"))
 	}
 	return i
 }
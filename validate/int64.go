@@ -1,11 +1,5 @@
 package validate
 
-import (
-	"net/http"
-
-	"github.com/pinguo/pgo2/perror"
-)
-
 // int64 validator for int64 value
 type Int64 struct {
 	Name   string
@@ -15,14 +9,14 @@ type Int64 struct {
 
 func (i *Int64) Min(v int64) *Int64 {
 	if !i.UseDft && i.Value < v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too small", i.Name))
+		panic(paramError(i.Name, "%s is too small"))
 	}
 	return i
 }
 
 func (i *Int64) Max(v int64) *Int64 {
 	if !i.UseDft && i.Value > v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too large", i.Name))
+		panic(paramError(i.Name, "%s is too large"))
 	}
 	return i
 }
@@ -37,7 +31,7 @@ func (i *Int64) Enum(enums ...int64) *Int64 {
 	}
 
 	if !i.UseDft && !found {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", i.Name))
+		panic(paramError(i.Name, "%s is invalid"))
 	}
 	return i
 }
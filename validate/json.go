@@ -1,9 +1,6 @@
 package validate
 
 import (
-	"net/http"
-
-	"github.com/pinguo/pgo2/perror"
 	"github.com/pinguo/pgo2/util"
 )
 
@@ -16,7 +13,7 @@ type Json struct {
 
 func (j *Json) Has(key string) *Json {
 	if v := util.MapGet(j.Value, key); !j.UseDft && v == nil {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s json field missing", j.Name))
+		panic(paramError(j.Name, "%s json field missing"))
 	}
 	return j
 }
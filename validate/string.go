@@ -3,13 +3,11 @@ package validate
 import (
 	"encoding/hex"
 	"encoding/json"
-	"net/http"
 	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
-	"github.com/pinguo/pgo2/perror"
 	"github.com/pinguo/pgo2/util"
 )
 
@@ -28,21 +26,21 @@ type String struct {
 
 func (s *String) Min(v int) *String {
 	if !s.UseDft && utf8.RuneCountInString(s.Value) < v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too short", s.Name))
+		panic(paramError(s.Name, "%s is too short"))
 	}
 	return s
 }
 
 func (s *String) Max(v int) *String {
 	if !s.UseDft && utf8.RuneCountInString(s.Value) > v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is too long", s.Name))
+		panic(paramError(s.Name, "%s is too long"))
 	}
 	return s
 }
 
 func (s *String) Len(v int) *String {
 	if !s.UseDft && utf8.RuneCountInString(s.Value) != v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s has invalid length", s.Name))
+		panic(paramError(s.Name, "%s has invalid length"))
 	}
 	return s
 }
@@ -57,7 +55,7 @@ func (s *String) Enum(enums ...string) *String {
 	}
 
 	if !s.UseDft && !found {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", s.Name))
+		panic(paramError(s.Name, "%s is invalid"))
 	}
 	return s
 }
@@ -71,7 +69,7 @@ func (s *String) RegExp(v interface{}) *String {
 	}
 
 	if !s.UseDft && !re.MatchString(s.Value) {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", s.Name))
+		panic(paramError(s.Name, "%s is invalid"))
 	}
 
 	return s
@@ -80,14 +78,14 @@ func (s *String) RegExp(v interface{}) *String {
 func (s *String) Filter(f func(v, n string) string) *String {
 	defer func() {
 		if v := recover(); !s.UseDft && v != nil {
-			panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", s.Name))
+			panic(paramError(s.Name, "%s is invalid"))
 		}
 	}()
 
 	if v := f(s.Value, s.Name); len(v) > 0 {
 		s.Value = v
 	} else if !s.UseDft {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid", s.Name))
+		panic(paramError(s.Name, "%s is invalid"))
 	}
 
 	return s
@@ -113,7 +111,7 @@ func (s *String) Password() *String {
 	}
 
 	if !s.UseDft && (!length || !number || !letter || !special) {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid password", s.Name))
+		panic(paramError(s.Name, "%s is invalid password"))
 	}
 
 	return s
@@ -121,7 +119,7 @@ func (s *String) Password() *String {
 
 func (s *String) Email() *String {
 	if !s.UseDft && !emailRe.MatchString(s.Value) {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid email", s.Name))
+		panic(paramError(s.Name, "%s is invalid email"))
 	}
 
 	return s
@@ -129,7 +127,7 @@ func (s *String) Email() *String {
 
 func (s *String) Mobile() *String {
 	if !s.UseDft && !mobileRe.MatchString(s.Value) {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid mobile", s.Name))
+		panic(paramError(s.Name, "%s is invalid mobile"))
 	}
 
 	return s
@@ -137,7 +135,7 @@ func (s *String) Mobile() *String {
 
 func (s *String) IPv4() *String {
 	if !s.UseDft && !ipv4Re.MatchString(s.Value) {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid ipv4", s.Name))
+		panic(paramError(s.Name, "%s is invalid ipv4"))
 	}
 
 	return s
@@ -146,11 +144,11 @@ func (s *String) IPv4() *String {
 func (s *String) MongoId() *String {
 	if !s.UseDft {
 		if len(s.Value) != 24 {
-			panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid MongoId", s.Name))
+			panic(paramError(s.Name, "%s is invalid MongoId"))
 		}
 		_, err := hex.DecodeString(s.Value)
 		if err != nil {
-			panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid MongoId", s.Name))
+			panic(paramError(s.Name, "%s is invalid MongoId"))
 		}
 	}
 
@@ -190,7 +188,7 @@ func (s *String) Json() *Json {
 	validator := &Json{s.Name, s.UseDft, make(map[string]interface{})}
 	decoder := json.NewDecoder(strings.NewReader(s.Value))
 	if err := decoder.Decode(&validator.Value); !s.UseDft && err != nil {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s is invalid json", s.Name))
+		panic(paramError(s.Name, "%s is invalid json"))
 	}
 
 	return validator
@@ -209,21 +207,21 @@ type StringSlice struct {
 
 func (s *StringSlice) Min(v int) *StringSlice {
 	if !s.UseDft && len(s.Value) < v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s has too few elements", s.Name))
+		panic(paramError(s.Name, "%s has too few elements"))
 	}
 	return s
 }
 
 func (s *StringSlice) Max(v int) *StringSlice {
 	if !s.UseDft && len(s.Value) > v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s has too many elements", s.Name))
+		panic(paramError(s.Name, "%s has too many elements"))
 	}
 	return s
 }
 
 func (s *StringSlice) Len(v int) *StringSlice {
 	if !s.UseDft && len(s.Value) != v {
-		panic(perror.NewWarn(http.StatusBadRequest, "%s has invalid length", s.Name))
+		panic(paramError(s.Name, "%s has invalid length"))
 	}
 	return s
 }
@@ -70,7 +70,7 @@ func Value(data interface{}, name string, dft ...interface{}) (interface{}, bool
 			value = dft[0]
 			useDft = true
 		} else {
-			panic(perror.NewWarn(http.StatusBadRequest, "%s is required", name))
+			panic(paramError(name, "%s is required"))
 		}
 	} else if strValue, strOk := value.(string); strOk {
 		strValue = strings.Trim(strValue, " \r\n\t")
@@ -80,9 +80,16 @@ func Value(data interface{}, name string, dft ...interface{}) (interface{}, bool
 			value = dft[0]
 			useDft = true
 		} else {
-			panic(perror.NewWarn(http.StatusBadRequest, "%s can't be empty", name))
+			panic(paramError(name, "%s can't be empty"))
 		}
 	}
 
 	return value, useDft
 }
+
+// paramError create 400 error of invalid param, name is the first
+// argument of format, the param is attached as invalidParams extension
+func paramError(name, format string, args ...interface{}) *perror.Error {
+	err := perror.NewWarn(http.StatusBadRequest, append([]interface{}{format, name}, args...)...)
+	return err.WithExtension(perror.ExtInvalidParams, []perror.InvalidParam{{Name: name, Reason: err.Message()}})
+}
@@ -2,6 +2,8 @@ package validate
 
 import (
 	"testing"
+
+	"github.com/pinguo/pgo2/perror"
 )
 
 func TestBoolData(t *testing.T) {
@@ -130,3 +132,19 @@ func TestValue(t *testing.T) {
 		Value(" ", "name")
 	})
 }
+
+func TestParamError(t *testing.T) {
+	defer func() {
+		err, ok := recover().(*perror.Error)
+		if !ok || err.Status() != 400 || err.Message() != "age is too small" {
+			t.FailNow()
+		}
+
+		params, ok := err.Extensions()[perror.ExtInvalidParams].([]perror.InvalidParam)
+		if !ok || len(params) != 1 || params[0].Name != "age" || params[0].Reason != err.Message() {
+			t.FailNow()
+		}
+	}()
+
+	IntData("1", "age").Min(10)
+}
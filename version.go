@@ -0,0 +1,147 @@
+package pgo2
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pinguo/pgo2/iface"
+)
+
+// DefaultVersionHeader request header of api version, eg. X-API-Version: 2
+const DefaultVersionHeader = "X-API-Version"
+
+// version segment of path, eg. v2
+var reVersion = regexp.MustCompile(`^[vV]([0-9]+)$`)
+
+// SetVersionHeader set request header of api version, empty to disable
+func (r *Router) SetVersionHeader(v string) {
+	r.versionHeader = v
+}
+
+// SetVersionMedia set vendor media type of api version,
+// eg. vnd.app matches Accept: application/vnd.app.v2+json
+func (r *Router) SetVersionMedia(v string) {
+	if v == "" {
+		r.versionMedia = nil
+		return
+	}
+
+	r.versionMedia = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(v) + `\.v([0-9]+)\b`)
+}
+
+// SetVersionFallback whether to fall back to the nearest lower
+// version if requested version of route not exists
+func (r *Router) SetVersionFallback(v bool) {
+	r.versionFallback = v
+}
+
+// addVersion record version segment of controller path, eg. /v2/user
+func (r *Router) addVersion(controllerPath string) {
+	for _, seg := range strings.Split(controllerPath, "/") {
+		m := reVersion.FindStringSubmatch(seg)
+		if m == nil {
+			continue
+		}
+
+		v, _ := strconv.Atoi(m[1])
+		if i := sort.SearchInts(r.versions, v); i == len(r.versions) || r.versions[i] != v {
+			r.versions = append(r.versions, 0)
+			copy(r.versions[i+1:], r.versions[i:])
+			r.versions[i] = v
+		}
+	}
+}
+
+// splitVersion split path into module prefix, version and the rest,
+// version segment is the first segment after module prefix,
+// eg. /fb/v2/user/view => /fb, 2, /user/view
+func (r *Router) splitVersion(path string) (prefix string, version int, rest string) {
+	for _, p := range r.modules {
+		if len(p) > len(prefix) && strings.HasPrefix(path, p+"/") {
+			prefix = p
+		}
+	}
+
+	rest = path[len(prefix):]
+	seg := strings.TrimLeft(rest, "/")
+	if i := strings.IndexByte(seg, '/'); i >= 0 {
+		seg = seg[:i]
+	}
+
+	if m := reVersion.FindStringSubmatch(seg); m != nil {
+		version, _ = strconv.Atoi(m[1])
+		rest = strings.TrimPrefix(strings.TrimLeft(rest, "/"), seg)
+	}
+
+	return
+}
+
+// Version get api version of request, version in path takes
+// precedence over header and media type, 0 if not specified.
+func (r *Router) Version(ctx iface.IContext) int {
+	if _, version, _ := r.splitVersion(ctx.Path()); version > 0 {
+		return version
+	}
+
+	return r.requestVersion(ctx)
+}
+
+// requestVersion get api version from header or media type of Accept
+func (r *Router) requestVersion(ctx iface.IContext) int {
+	if r.versionHeader != "" {
+		if v := ctx.Header(r.versionHeader, ""); v != "" {
+			if m := reVersion.FindStringSubmatch(v); m != nil {
+				v = m[1]
+			}
+
+			if n, e := strconv.Atoi(v); e == nil && n > 0 {
+				return n
+			}
+		}
+	}
+
+	if r.versionMedia != nil {
+		if m := r.versionMedia.FindStringSubmatch(ctx.Header("Accept", "")); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+	}
+
+	return 0
+}
+
+// versionPaths get paths to resolve in order for requested api version,
+// eg. /user/view with version 3 => /v3/user/view, /v2/user/view, /user/view
+// if v2 is the nearest lower version and fallback is enabled.
+func (r *Router) versionPaths(ctx iface.IContext, path string) []string {
+	if len(r.versions) == 0 || ModeWeb != App().mode {
+		return []string{path}
+	}
+
+	prefix, version, rest := r.splitVersion(path)
+	if version == 0 {
+		if version = r.requestVersion(ctx); version == 0 {
+			return []string{path}
+		}
+	}
+
+	paths := []string{prefix + "/v" + strconv.Itoa(version) + rest}
+	if !r.versionFallback {
+		return paths
+	}
+
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if v := r.versions[i]; v < version {
+			paths = append(paths, prefix+"/v"+strconv.Itoa(v)+rest)
+		}
+	}
+
+	// unversioned route is the lowest version
+	if prefix+rest == "" {
+		return append(paths, "/")
+	}
+
+	return append(paths, prefix+rest)
+}
@@ -0,0 +1,114 @@
+package pgo2
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pinguo/pgo2/util"
+)
+
+// vhost virtual host, host is exact name like api.example.com or
+// wildcard like *.example.com which matches any sub domain.
+type vhost struct {
+	host            string
+	prefix          string   // controller path prefix
+	errorController string   // error controller of host, default is router's
+	plugins         []string // plugin chain of host, default is server's
+}
+
+// vhosts virtual host list
+type vhosts struct {
+	exact    map[string]*vhost
+	wildcard []*vhost // sorted by length of host desc
+	prefixes []string // prefixes reserved by hosts
+}
+
+func newVhosts(list []interface{}) *vhosts {
+	v := &vhosts{exact: make(map[string]*vhost)}
+	for _, item := range list {
+		conf, ok := item.(map[string]interface{})
+		if !ok {
+			panic("Router: invalid host config: " + util.ToString(item))
+		}
+
+		h := &vhost{host: strings.ToLower(util.ToString(conf["host"]))}
+		if h.host == "" {
+			panic("Router: host is required: " + util.ToString(item))
+		}
+
+		if prefix, ok := conf["prefix"]; ok {
+			h.prefix = strings.TrimRight(util.CleanPath(util.ToString(prefix)), "/")
+		}
+
+		if errCtl, ok := conf["errorController"]; ok {
+			h.errorController = util.ToString(errCtl)
+		}
+
+		if plugins, ok := conf["plugins"].([]interface{}); ok {
+			h.plugins = make([]string, 0, len(plugins))
+			for _, name := range plugins {
+				h.plugins = append(h.plugins, util.ToString(name))
+			}
+		}
+
+		if strings.HasPrefix(h.host, "*.") {
+			v.wildcard = append(v.wildcard, h)
+		} else {
+			v.exact[h.host] = h
+		}
+
+		if h.prefix != "" {
+			v.prefixes = append(v.prefixes, strings.ToLower(h.prefix))
+		}
+	}
+
+	sort.SliceStable(v.wildcard, func(i, j int) bool {
+		return len(v.wildcard[i].host) > len(v.wildcard[j].host)
+	})
+
+	return v
+}
+
+// match find virtual host by host header, port is ignored,
+// exact host takes precedence over wildcard host.
+func (v *vhosts) match(host string) *vhost {
+	if h, _, e := net.SplitHostPort(host); e == nil {
+		host = h
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if h, ok := v.exact[host]; ok {
+		return h
+	}
+
+	for _, h := range v.wildcard {
+		if strings.HasSuffix(host, h.host[1:]) {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// reserved check whether path is under prefix reserved by host
+func (v *vhosts) reserved(path string) bool {
+	path = strings.ToLower(path)
+	for _, prefix := range v.prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// all get all virtual hosts
+func (v *vhosts) all() []*vhost {
+	list := make([]*vhost, 0, len(v.exact)+len(v.wildcard))
+	for _, h := range v.exact {
+		list = append(list, h)
+	}
+
+	return append(list, v.wildcard...)
+}
@@ -0,0 +1,60 @@
+package pgo2
+
+import (
+	"testing"
+)
+
+func TestVhosts_Match(t *testing.T) {
+	hosts := newVhosts([]interface{}{
+		map[string]interface{}{"host": "api.example.com", "prefix": "/api/"},
+		map[string]interface{}{"host": "*.example.com", "prefix": "/www"},
+		map[string]interface{}{"host": "*.admin.example.com", "prefix": "/admin", "plugins": []interface{}{"gzip"}},
+	})
+
+	cases := map[string]string{
+		"api.example.com":        "/api",
+		"API.example.com:8000":   "/api",
+		"foo.example.com":        "/www",
+		"foo.admin.example.com.": "/admin",
+		"example.com":            "",
+		"foo.example.org":        "",
+	}
+
+	for host, want := range cases {
+		vh := hosts.match(host)
+		if (vh == nil && want != "") || (vh != nil && vh.prefix != want) {
+			t.Fatalf("match(%q) failed, want %q", host, want)
+		}
+	}
+
+	if !hosts.reserved("/Admin/user") || !hosts.reserved("/api") || hosts.reserved("/apis") {
+		t.Fatal("reserved failed")
+	}
+
+	if len(hosts.all()) != 3 {
+		t.FailNow()
+	}
+}
+
+func TestRouter_hostPath(t *testing.T) {
+	router := NewRouter(map[string]interface{}{"hosts": []interface{}{
+		map[string]interface{}{"host": "api.example.com", "prefix": "/api"},
+	}})
+
+	vh := router.hosts.match("api.example.com")
+	if p, ok := router.hostPath(vh, "/user/view"); !ok || p != "/api/user/view" {
+		t.Fatal(p)
+	}
+
+	if p, _ := router.hostPath(vh, "/"); p != "/api/index" {
+		t.Fatal(p)
+	}
+
+	if _, ok := router.hostPath(nil, "/api/user/view"); ok {
+		t.Fatal("prefix of host should be reserved")
+	}
+
+	if p, ok := router.hostPath(nil, "/user/view"); !ok || p != "/user/view" {
+		t.Fatal(p)
+	}
+}
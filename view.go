@@ -17,6 +17,8 @@ import (
 //     commons:
 //         - "@view/common/header.html"
 //         - "@view/common/footer.html"
+// builtin funcs:
+//     url: generate url of action, eg. {{url "/api/user" "View" .Id}}
 func NewView(config map[string]interface{}) *View {
 	view := &View{
 		suffix:    ".html",
@@ -110,6 +112,9 @@ func (v *View) loadTemplate(view string) {
 
 	tpl := template.New(filepath.Base(view))
 
+	// add builtin func map, it can be overridden by custom func map
+	tpl.Funcs(template.FuncMap{"url": viewUrl})
+
 	// add custom func map
 	if len(v.funcMap) > 0 {
 		tpl.Funcs(v.funcMap)
@@ -124,6 +129,12 @@ func (v *View) loadTemplate(view string) {
 	v.templates[view] = tpl
 }
 
+// viewUrl generate url of action in template,
+// eg. {{url "/api/user" "View" .Id}}, see Router.URL
+func viewUrl(controllerId, actionId string, params ...interface{}) (string, error) {
+	return App().Router().URL(controllerId, actionId, params...)
+}
+
 func (v *View) normalize(view string) string {
 	if ext := filepath.Ext(view); len(ext) == 0 {
 		view = view + v.suffix